@@ -0,0 +1,70 @@
+package examples
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yoshd/protoc-gen-stest/examples/pb"
+)
+
+func TestScenarioActionCounts(t *testing.T) {
+	assert := assert.New(t)
+	testClient, _, _ := startSampleServer(t)
+	testClient.RunGRPCTest(
+		t,
+		"scenario/action_counts.json",
+		responseCompareFuncMap,
+	)
+	// The calls of the previous scenarios are not counted.
+	testClient.RunGRPCTest(
+		t,
+		"scenario/action_counts.json",
+		responseCompareFuncMap,
+	)
+	assert.Equal(map[string]int{"Hello": 6}, testClient.ActionCounts())
+}
+
+func TestScenarioActionCountsMismatch(t *testing.T) {
+	assert := assert.New(t)
+	testClient, _, _ := startSampleServer(t)
+	var failures []string
+	reporter := &recordingReporter{failures: &failures}
+	testClient.RunScenario(reporter, "scenario/action_counts_mismatch.json", nil)
+	assert.Equal([]string{
+		"the action GetUser ran 0 times in the scenario. Expected: 1\n" +
+			"the action Hello ran 2 times in the scenario. Expected: 1",
+	}, failures)
+}
+
+func TestScenarioIncludeActionCounts(t *testing.T) {
+	assert := assert.New(t)
+	testClient, _, _ := startSampleServer(t)
+	var failures []string
+	reporter := &recordingReporter{failures: &failures}
+	testClient.RunScenario(reporter, "scenario/include_action_counts.json", nil)
+	// The expected_action_counts of the included files are summed.
+	assert.Equal([]string{
+		"the action GetUser ran 0 times in the scenario. Expected: 1\n" +
+			"the action Hello ran 5 times in the scenario. Expected: 4",
+	}, failures)
+}
+
+func TestScenarioActionCountsInvalid(t *testing.T) {
+	assert := assert.New(t)
+	testClient, _, _ := startSampleServer(t)
+	var failures []string
+	reporter := &recordingReporter{failures: &failures}
+	testClient.RunScenario(reporter, "scenario/action_counts_invalid.json", nil)
+	assert.Equal([]string{
+		"Scenario JSON is invalid. Because the expected_action_counts of Hello is not a number.",
+	}, failures)
+	var problems []string
+	for _, err := range pb.SampleLintScenario("scenario/action_counts_invalid.json") {
+		problems = append(problems, err.Error())
+	}
+	assert.Equal([]string{
+		"the expected_action_counts of GetUser is not a non-negative integer",
+		"the expected_action_counts of Hello is not a non-negative integer",
+	}, problems)
+}
@@ -0,0 +1,34 @@
+package examples
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/yoshd/protoc-gen-stest/examples/pb"
+)
+
+func TestScenarioAfterAll(t *testing.T) {
+	assert := assert.New(t)
+	testClient, _, _ := startSampleServer(t)
+	calls := 0
+	testClient.AfterAll = func(t pb.SampleReporter, captures map[string]interface{}) {
+		calls++
+		assert.Equal("Hello!", captures["token"])
+		responses := captures["$responses"].([]proto.Message)
+		if assert.Len(responses, 2) {
+			assert.Equal("Bye!", responses[1].(*pb.ByeResponse).ResMsg)
+		}
+		for name := range captures {
+			assert.True(name == "$responses" || !strings.HasPrefix(name, "$"), name)
+		}
+	}
+	testClient.RunGRPCTest(
+		t,
+		"scenario/metadata.json",
+		responseCompareFuncMap,
+	)
+	assert.Equal(1, calls)
+}
@@ -0,0 +1,29 @@
+package examples
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScenarioAssertions(t *testing.T) {
+	testClient, _, _ := startSampleServer(t)
+	testClient.RunGRPCTest(
+		t,
+		"scenario/assertions.json",
+		responseCompareFuncMap,
+	)
+}
+
+func TestScenarioAssertionsFailure(t *testing.T) {
+	assert := assert.New(t)
+	testClient, _, _ := startSampleServer(t)
+	var failures []string
+	reporter := &recordingReporter{failures: &failures}
+	testClient.RunScenario(reporter, "scenario/assertions_failure.json", nil)
+	assert.Equal([]string{
+		"scenario/assertions_failure.json: the test case 0: the assertion 0 failed: the actual response of the Hello was not equal to the expected response\n" +
+			"the assertion 2 failed: the response of Hello is not an error as expected\n" +
+			"the assertion 3 failed: the trailer x-retry-count of the response of Hello was 2, which is not 3",
+	}, failures)
+}
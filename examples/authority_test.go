@@ -0,0 +1,54 @@
+package examples
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/yoshd/protoc-gen-stest/examples/pb"
+)
+
+// authorityCredentials sends the authority of the call, which is the host of the audience, as the x-authority metadata.
+type authorityCredentials struct{}
+
+func (authorityCredentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	u, err := url.Parse(uri[0])
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{"x-authority": u.Host}, nil
+}
+
+func (authorityCredentials) RequireTransportSecurity() bool {
+	return false
+}
+
+func TestScenarioAuthority(t *testing.T) {
+	assert := assert.New(t)
+	s, im, _ := newSampleServer()
+	lis := bufconn.Listen(1024 * 1024)
+	go s.Serve(lis)
+	defer s.Stop()
+
+	dialer := func(ctx context.Context, target string) (net.Conn, error) {
+		return lis.Dial()
+	}
+	testClient, err := pb.NewTestClientForTarget("bufnet", grpc.WithContextDialer(dialer), grpc.WithInsecure(), grpc.WithPerRPCCredentials(authorityCredentials{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer testClient.Close()
+	testClient.RunGRPCTest(
+		t,
+		"scenario/authority.json",
+		responseCompareFuncMap,
+	)
+
+	assert.Equal([]string{"hello.example.com"}, im.get("/Sample/Hello", "x-authority"))
+	assert.Equal([]string{"bufnet"}, im.get("/Sample/Bye", "x-authority"))
+}
@@ -0,0 +1,61 @@
+package examples
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestScenarioBaseContext(t *testing.T) {
+	assert := assert.New(t)
+	testClient, im, _ := startSampleServer(t)
+	testClient.BaseContext = metadata.AppendToOutgoingContext(
+		context.Background(),
+		"x-parent", "yoshd",
+		"authorization", "Bearer parent",
+	)
+	testClient.RunGRPCTest(
+		t,
+		"scenario/metadata.json",
+		responseCompareFuncMap,
+	)
+
+	assert.Equal([]string{"yoshd"}, im.get("/Sample/Hello", "x-parent"))
+	assert.Equal([]string{"Bearer parent"}, im.get("/Sample/Hello", "authorization"))
+	assert.Equal([]string{"yoshd"}, im.get("/Sample/Bye", "x-parent"))
+	assert.Equal([]string{"Bearer Hello!"}, im.get("/Sample/Bye", "authorization"))
+}
+
+func TestRunGRPCTestContext(t *testing.T) {
+	assert := assert.New(t)
+	testClient, im, _ := startSampleServer(t)
+	// The context given to RunGRPCTestContext is used instead of BaseContext.
+	testClient.BaseContext = metadata.AppendToOutgoingContext(context.Background(), "x-parent", "base")
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-parent", "yoshd")
+	testClient.RunGRPCTestContext(ctx, t, "scenario/metadata.json", responseCompareFuncMap)
+	assert.Equal([]string{"yoshd"}, im.get("/Sample/Hello", "x-parent"))
+}
+
+// contextReporter is the recordingReporter having the context of the test as the Reporter returned by NewTestingReporter.
+type contextReporter struct {
+	*recordingReporter
+	ctx context.Context
+}
+
+func (r *contextReporter) Context() context.Context {
+	return r.ctx
+}
+
+func TestScenarioAbortedContext(t *testing.T) {
+	assert := assert.New(t)
+	testClient, _, _ := startSampleServer(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var failures []string
+	reporter := &contextReporter{recordingReporter: &recordingReporter{failures: &failures}, ctx: ctx}
+	testClient.RunScenario(reporter, "scenario/user.json", nil)
+	assert.Equal([]string{"the scenario scenario/user.json was aborted before the test case 0: context canceled"}, failures)
+	assert.Empty(testClient.ActionCounts())
+}
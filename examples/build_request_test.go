@@ -0,0 +1,22 @@
+package examples
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildRequest(t *testing.T) {
+	assert := assert.New(t)
+	testClient, _, _ := startSampleServer(t)
+	req, err := testClient.BuildGetUserRequest(map[string]interface{}{"id": "yoshd"})
+	assert.NoError(err)
+	assert.Equal("yoshd", req.Id)
+	res, err := testClient.Client.GetUser(context.Background(), req)
+	assert.NoError(err)
+	assert.Equal("Yoshi", res.Name)
+
+	_, err = testClient.BuildGetUserRequest(map[string]interface{}{"id": 1})
+	assert.Error(err)
+}
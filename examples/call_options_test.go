@@ -0,0 +1,38 @@
+package examples
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	_ "google.golang.org/grpc/encoding/gzip"
+)
+
+func TestScenarioCallOptions(t *testing.T) {
+	testClient, _, _ := startSampleServer(t)
+	testClient.RunGRPCTest(
+		t,
+		"scenario/call_options.json",
+		responseCompareFuncMap,
+	)
+}
+
+func TestScenarioGRPCEncoding(t *testing.T) {
+	testClient, _, _ := startSampleServer(t)
+	// The server compresses the response with the compressor of the request.
+	testClient.RunGRPCTest(
+		t,
+		"scenario/grpc_encoding.json",
+		nil,
+	)
+}
+
+func TestScenarioGRPCEncodingMissing(t *testing.T) {
+	assert := assert.New(t)
+	testClient, _, _ := startSampleServer(t)
+	var failures []string
+	reporter := &recordingReporter{failures: &failures}
+	testClient.RunScenario(reporter, "scenario/grpc_encoding_missing.json", nil)
+	assert.Equal([]string{
+		`scenario/grpc_encoding_missing.json: the test case 0: the assertion 0 failed: the header grpc-encoding of the response of Hello was [], which is not "gzip"`,
+	}, failures)
+}
@@ -0,0 +1,29 @@
+package examples
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+)
+
+func TestScenarioCallStats(t *testing.T) {
+	assert := assert.New(t)
+	testClient, _, _ := startSampleServer(t)
+	testClient.RunGRPCTest(
+		t,
+		"scenario/user.json",
+		responseCompareFuncMap,
+	)
+	testClient.RunGRPCTest(
+		t,
+		"scenario/metadata.json",
+		responseCompareFuncMap,
+	)
+
+	assert.Equal(map[string]map[codes.Code]int{
+		"GetUser": {codes.OK: 1, codes.NotFound: 1},
+		"Hello":   {codes.OK: 1},
+		"Bye":     {codes.OK: 1},
+	}, testClient.CallStats())
+}
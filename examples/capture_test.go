@@ -0,0 +1,37 @@
+package examples
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/yoshd/protoc-gen-stest/examples/pb"
+)
+
+func TestCaptureScenario(t *testing.T) {
+	assert := assert.New(t)
+	testClient, _, _ := startSampleServer(t)
+	before := testClient.CaptureScenario(pb.NewSampleTestingReporter(t), "scenario/user.json")
+	if !assert.Len(before, 2) {
+		return
+	}
+	assert.Equal("GetUser", before[0].Name)
+	assert.Equal("Yoshi", before[0].Response.(*pb.User).Name)
+	assert.Nil(before[1].Response)
+	assert.Equal(codes.NotFound, status.Code(before[1].Err))
+
+	after := testClient.CaptureScenario(pb.NewSampleTestingReporter(t), "scenario/user.json")
+	assert.Empty(testClient.DiffCaptures(before, after))
+
+	// The changes of the server are simulated by changing the outcomes.
+	after[0].Response.(*pb.User).Name = "Yoshida"
+	after[1].Err = status.Error(codes.PermissionDenied, "banned")
+	diff := testClient.DiffCaptures(before, after)
+	assert.Contains(diff, "the test case 0 GetUser differs (-before +after):")
+	assert.Contains(diff, "Yoshida")
+	assert.Contains(diff, "the test case 1 GetUser differs (-before +after):")
+	assert.Contains(diff, "banned")
+	assert.Contains(testClient.DiffCaptures(before, after[:1]), "the test case 1 GetUser was captured only before")
+}
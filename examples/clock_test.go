@@ -0,0 +1,62 @@
+package examples
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yoshd/protoc-gen-stest/examples/pb"
+)
+
+// fakeClock is the clock advancing by the duration of each timer at once instead of waiting for it.
+type fakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []time.Duration
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	c.timers = append(c.timers, d)
+	ch := make(chan time.Time, 1)
+	ch <- c.now
+	return ch
+}
+
+func TestScenarioClock(t *testing.T) {
+	assert := assert.New(t)
+	testClient, _, _ := startSampleServer(t)
+	clock := &fakeClock{now: time.Date(2020, 10, 14, 0, 0, 0, 0, time.UTC)}
+	testClient.Clock = clock
+	// The delay, the sleep and the timeout of the scenario elapse on the fake clock without waiting for them.
+	start := time.Now()
+	testClient.RunGRPCTest(t, "scenario/clock.json", nil)
+	assert.Less(int64(time.Since(start)), int64(time.Minute))
+	assert.Equal([]time.Duration{time.Minute, time.Hour, 10 * time.Minute}, clock.timers)
+}
+
+func TestScenarioReplay(t *testing.T) {
+	assert := assert.New(t)
+	testClient, _, _ := startSampleServer(t)
+	clock := &fakeClock{now: time.Date(2020, 10, 14, 0, 0, 0, 0, time.UTC)}
+	testClient.Clock = clock
+	testClient.RunGRPCTest(t, "scenario/replay.json", nil)
+	assert.Equal([]time.Duration{2 * time.Second, 5 * time.Second, time.Second, 10 * time.Second, 3 * time.Second}, clock.timers)
+
+	// The delays are shortened by the time since the previous test cases started, which the sleeps elapse in,
+	// and the last test case starts at once after the sleep longer than its delay.
+	clock = &fakeClock{now: time.Date(2020, 10, 14, 0, 0, 0, 0, time.UTC)}
+	testClient.Clock = clock
+	testClient.RunGRPCTestWithOptions(t, "scenario/replay.json", pb.SampleRunOptions{Replay: true})
+	assert.Equal([]time.Duration{2 * time.Second, 3 * time.Second, time.Second, 10 * time.Second}, clock.timers)
+}
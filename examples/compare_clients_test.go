@@ -0,0 +1,46 @@
+package examples
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+
+	"github.com/yoshd/protoc-gen-stest/examples/pb"
+)
+
+// shimClient is the client answering Hello by itself instead of calling the server.
+type shimClient struct {
+	pb.SampleClient
+	resMsg string
+}
+
+func (c *shimClient) Hello(ctx context.Context, in *pb.HelloRequest, opts ...grpc.CallOption) (*pb.HelloResponse, error) {
+	return &pb.HelloResponse{ResMsg: c.resMsg}, nil
+}
+
+func TestScenarioCompareClients(t *testing.T) {
+	testClient, _, _ := startSampleServer(t)
+	otherClient, _, _ := startSampleServer(t)
+	testClient.CompareClients = []pb.SampleClient{otherClient.Client, &shimClient{SampleClient: otherClient.Client, resMsg: "Hello!"}}
+	testClient.RunGRPCTest(
+		t,
+		"scenario/assertions.json",
+		nil,
+	)
+}
+
+func TestScenarioCompareClientsDivergence(t *testing.T) {
+	assert := assert.New(t)
+	testClient, _, _ := startSampleServer(t)
+	otherClient, _, _ := startSampleServer(t)
+	testClient.CompareClients = []pb.SampleClient{otherClient.Client, &shimClient{SampleClient: otherClient.Client, resMsg: "Hi!"}}
+	var failures []string
+	reporter := &recordingReporter{failures: &failures}
+	testClient.RunScenario(reporter, "scenario/assertions.json", nil)
+	assert.Equal([]string{
+		"scenario/assertions.json: the test case 0: the clients diverged on Hello:\n" +
+			`the client 1 returned {"res_msg":"Hi!"}, but Client returned {"res_msg":"Hello!"}`,
+	}, failures)
+}
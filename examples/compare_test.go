@@ -0,0 +1,18 @@
+package examples
+
+import (
+	"testing"
+
+	"github.com/yoshd/protoc-gen-stest/examples/pb"
+)
+
+func TestCompareHello(t *testing.T) {
+	var tester pb.SampleTester = pb.NewTestClient(nil)
+	compareFunc := responseCompareFuncMap["Hello"]
+	if err := tester.CompareHello(&pb.HelloResponse{ResMsg: "Hello"}, &pb.HelloResponse{ResMsg: "Hello"}, compareFunc); err != nil {
+		t.Errorf("the same responses were not equal: %v", err)
+	}
+	if err := tester.CompareHello(&pb.HelloResponse{ResMsg: "Hello"}, &pb.HelloResponse{ResMsg: "Bye"}, compareFunc); err == nil {
+		t.Error("the different responses were equal")
+	}
+}
@@ -0,0 +1,14 @@
+package examples
+
+import (
+	"testing"
+)
+
+func TestScenarioCompressed(t *testing.T) {
+	testClient, _, _ := startSampleServer(t)
+	testClient.RunGRPCTest(
+		t,
+		"scenario/compressed.json.gz",
+		responseCompareFuncMap,
+	)
+}
@@ -0,0 +1,35 @@
+package examples
+
+import (
+	"sync"
+	"testing"
+)
+
+// TestScenarioConcurrent runs the scenarios on one runner from multiple goroutines,
+// so that go test -race finds the state of the runner shared without a guard.
+// The expected_action_counts of a scenario do not count the calls of the others.
+func TestScenarioConcurrent(t *testing.T) {
+	testClient, _, _ := startSampleServer(t)
+	scenarios := []string{
+		"scenario/user.json",
+		"scenario/matcher.json",
+		"scenario/ref.json",
+		"scenario/random.json",
+		"scenario/template.json",
+		"scenario/matrix.json",
+		"scenario/assertions.json",
+		"scenario/error_reason.json",
+		"scenario/action_counts.json",
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		for _, scenario := range scenarios {
+			wg.Add(1)
+			go func(scenario string) {
+				defer wg.Done()
+				testClient.RunGRPCTest(t, scenario, responseCompareFuncMap)
+			}(scenario)
+		}
+	}
+	wg.Wait()
+}
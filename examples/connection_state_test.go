@@ -0,0 +1,31 @@
+package examples
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yoshd/protoc-gen-stest/examples/pb"
+)
+
+func TestConnectionState(t *testing.T) {
+	assert := assert.New(t)
+	testClient, _, _ := startSampleServer(t)
+	testClient.LogConnectionState = true
+	var failures, logs []string
+	reporter := &recordingReporter{failures: &failures, logs: &logs}
+	testClient.RunScenario(reporter, "scenario/error_reason_mismatch.json", nil)
+	assert.NotEmpty(failures)
+	assert.Contains(logs, "the state of the connections:\nbufnet: READY")
+	assert.Equal("bufnet: READY", testClient.ConnectionState())
+
+	// The passing test cases log nothing.
+	logs = nil
+	testClient.RunScenario(reporter, "scenario/user.json", nil)
+	for _, log := range logs {
+		assert.False(strings.HasPrefix(log, "the state of the connections:"), log)
+	}
+
+	assert.Equal("", (&pb.SampleTestRunner{}).ConnectionState())
+}
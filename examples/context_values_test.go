@@ -0,0 +1,47 @@
+package examples
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/yoshd/protoc-gen-stest/examples/pb"
+)
+
+func TestScenarioContextValues(t *testing.T) {
+	assert := assert.New(t)
+	s, _, _ := newSampleServer()
+	lis := bufconn.Listen(1024 * 1024)
+	go s.Serve(lis)
+	defer s.Stop()
+
+	var mu sync.Mutex
+	values := map[string][]interface{}{}
+	interceptor := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		mu.Lock()
+		values[method] = []interface{}{ctx.Value("tenant"), ctx.Value("retries")}
+		mu.Unlock()
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+	dialer := func(ctx context.Context, target string) (net.Conn, error) {
+		return lis.Dial()
+	}
+	testClient, err := pb.NewTestClientForTarget("bufnet", grpc.WithContextDialer(dialer), grpc.WithInsecure(), grpc.WithUnaryInterceptor(interceptor))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer testClient.Close()
+	testClient.RunGRPCTest(
+		t,
+		"scenario/context_values.json",
+		responseCompareFuncMap,
+	)
+
+	assert.Equal([]interface{}{"yoshd", float64(3)}, values["/Sample/Hello"])
+	assert.Equal([]interface{}{nil, nil}, values["/Sample/Bye"])
+}
@@ -0,0 +1,23 @@
+package examples
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/yoshd/protoc-gen-stest/examples/pb"
+)
+
+func TestScenarioFullCoverage(t *testing.T) {
+	testClient := pb.NewTestClient(nil)
+	testClient.AssertFullCoverage(t, "scenario/sample.json")
+
+	testClient.WarnUncoveredActions = true
+	testClient.AssertFullCoverage(t, "scenario/health.json")
+}
+
+func TestActions(t *testing.T) {
+	expected := []string{"Hello", "Bye", "GetUser"}
+	if !reflect.DeepEqual(expected, pb.SampleActions) {
+		t.Errorf("the actions were %v. Expected: %v", pb.SampleActions, expected)
+	}
+}
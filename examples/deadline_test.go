@@ -0,0 +1,37 @@
+package examples
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScenarioDeadline(t *testing.T) {
+	testClient, _, _ := startSampleServer(t)
+	testClient.RunGRPCTest(
+		t,
+		"scenario/deadline.json",
+		nil,
+	)
+}
+
+func TestScenarioDeadlinePropagation(t *testing.T) {
+	testClient, _, _ := startSampleServer(t)
+	testClient.RunGRPCTest(
+		t,
+		"scenario/deadline_propagation.json",
+		nil,
+	)
+}
+
+func TestScenarioDeadlinePropagationFailure(t *testing.T) {
+	assert := assert.New(t)
+	testClient, _, _ := startSampleServer(t)
+	var failures []string
+	reporter := &recordingReporter{failures: &failures}
+	testClient.RunScenario(reporter, "scenario/deadline_propagation_failure.json", nil)
+	assert.Equal([]string{
+		"scenario/deadline_propagation_failure.json: the test case 0: the assertion 0 failed: the remaining time 0s of the deadline in the field remaining_ms of the response of Hello is not within 100ms of the timeout 5s\n" +
+			"the assertion 1 failed: the field res_msg of the response of Hello is neither a duration nor a number",
+	}, failures)
+}
@@ -0,0 +1,42 @@
+package examples
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+)
+
+// decodeNDJSON decodes the scenario having a test case per line.
+func decodeNDJSON(data []byte) ([]map[string]interface{}, error) {
+	var scenario []map[string]interface{}
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		var testCase map[string]interface{}
+		if err := json.Unmarshal(line, &testCase); err != nil {
+			return nil, err
+		}
+		scenario = append(scenario, testCase)
+	}
+	return scenario, nil
+}
+
+func TestScenarioDecoder(t *testing.T) {
+	assert := assert.New(t)
+	testClient, _, _ := startSampleServer(t)
+	testClient.Decoder = decodeNDJSON
+	testClient.RunGRPCTest(
+		t,
+		"scenario/hello.ndjson",
+		responseCompareFuncMap,
+	)
+
+	assert.Equal(map[string]map[codes.Code]int{
+		"Hello":   {codes.OK: 1},
+		"GetUser": {codes.NotFound: 1},
+	}, testClient.CallStats())
+}
@@ -0,0 +1,95 @@
+package examples
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yoshd/protoc-gen-stest/examples/pb"
+	"google.golang.org/grpc"
+)
+
+func TestScenarioUnixSocket(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	socket := filepath.Join(dir, "sample.sock")
+	lis, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, _, _ := newSampleServer()
+	go s.Serve(lis)
+	defer s.Stop()
+
+	dialer := func(ctx context.Context, _ string) (net.Conn, error) {
+		return (&net.Dialer{}).DialContext(ctx, "unix", socket)
+	}
+	testClient, err := pb.NewTestClientForTarget(socket, grpc.WithContextDialer(dialer), grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer testClient.Close()
+	testClient.RunGRPCTest(
+		t,
+		"scenario/user.json",
+		responseCompareFuncMap,
+	)
+}
+
+func TestScenarioBlockingDial(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	socket := filepath.Join(dir, "sample.sock")
+	s, _, _ := newSampleServer()
+	defer s.Stop()
+	// The server starts listening after the dial has begun, as a server booting in the same process as the test.
+	go func() {
+		time.Sleep(300 * time.Millisecond)
+		lis, err := net.Listen("unix", socket)
+		if err != nil {
+			return
+		}
+		s.Serve(lis)
+	}()
+
+	dialer := func(ctx context.Context, _ string) (net.Conn, error) {
+		return (&net.Dialer{}).DialContext(ctx, "unix", socket)
+	}
+	config := pb.SampleDialConfig{Timeout: 5 * time.Second, MinBackoff: 50 * time.Millisecond, MaxBackoff: 100 * time.Millisecond}
+	testClient, err := pb.NewTestClientForTargetBlocking(context.Background(), socket, config, grpc.WithContextDialer(dialer), grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer testClient.Close()
+	testClient.RunGRPCTest(
+		t,
+		"scenario/user.json",
+		responseCompareFuncMap,
+	)
+}
+
+func TestBlockingDialTimeout(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	socket := filepath.Join(dir, "none.sock")
+	dialer := func(ctx context.Context, _ string) (net.Conn, error) {
+		return (&net.Dialer{}).DialContext(ctx, "unix", socket)
+	}
+	config := pb.SampleDialConfig{Timeout: 200 * time.Millisecond}
+	_, err = pb.NewTestClientForTargetBlocking(context.Background(), socket, config, grpc.WithContextDialer(dialer), grpc.WithInsecure())
+	assert.Error(t, err)
+}
@@ -0,0 +1,37 @@
+package examples
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+func TestScenarioDynamicFields(t *testing.T) {
+	testClient, _, _ := startSampleServer(t)
+	testClient.RunGRPCTest(t, "scenario/dynamic.json", nil)
+}
+
+func TestBuildRequestStruct(t *testing.T) {
+	assert := assert.New(t)
+	testClient, _, _ := startSampleServer(t)
+	req, err := testClient.BuildGetUserRequest(map[string]interface{}{"id": "yoshd", "options": map[string]interface{}{"verbose": true}})
+	if assert.NoError(err) {
+		assert.True(req.GetOptions().GetFields()["verbose"].GetBoolValue())
+	}
+}
+
+func TestScenarioTypeResolver(t *testing.T) {
+	assert := assert.New(t)
+	testClient, _, _ := startSampleServer(t)
+	// The type of the google.protobuf.Any of the expected response is not found in the empty registry.
+	testClient.TypeResolver = new(protoregistry.Types)
+	var failures []string
+	reporter := &recordingReporter{failures: &failures}
+	testClient.RunScenario(reporter, "scenario/dynamic.json", nil)
+	if assert.Len(failures, 1) {
+		assert.True(strings.HasPrefix(failures[0], "scenario/dynamic.json: the test case 0: the expected_response of the GetUser can not be decoded: "))
+		assert.Contains(failures[0], "type.googleapis.com/Profile")
+	}
+}
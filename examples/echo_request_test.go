@@ -0,0 +1,28 @@
+package examples
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScenarioEchoRequest(t *testing.T) {
+	testClient, _, _ := startSampleServer(t)
+	testClient.RunGRPCTest(
+		t,
+		"scenario/echo_request.json",
+		nil,
+	)
+}
+
+func TestScenarioEchoRequestFailure(t *testing.T) {
+	assert := assert.New(t)
+	testClient, _, _ := startSampleServer(t)
+	var failures []string
+	reporter := &recordingReporter{failures: &failures}
+	testClient.RunScenario(reporter, "scenario/echo_request_failure.json", nil)
+	assert.Equal([]string{
+		"scenario/echo_request_failure.json: the test case 0: the field name of $echoRequest is not in the request",
+		"scenario/echo_request_failure.json: the test case 1: the field options of $echoRequest is not in the response of the GetUser",
+	}, failures)
+}
@@ -0,0 +1,14 @@
+package examples
+
+import (
+	"testing"
+)
+
+func TestScenarioExpectedErrorCodes(t *testing.T) {
+	testClient, _, _ := startSampleServer(t)
+	testClient.RunGRPCTest(
+		t,
+		"scenario/error_codes.json",
+		responseCompareFuncMap,
+	)
+}
@@ -0,0 +1,14 @@
+package examples
+
+import (
+	"testing"
+)
+
+func TestScenarioErrorObject(t *testing.T) {
+	testClient, _, _ := startSampleServer(t)
+	testClient.RunGRPCTest(
+		t,
+		"scenario/error_object.json",
+		responseCompareFuncMap,
+	)
+}
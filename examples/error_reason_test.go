@@ -0,0 +1,28 @@
+package examples
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScenarioErrorReason(t *testing.T) {
+	testClient, _, _ := startSampleServer(t)
+	testClient.RunGRPCTest(
+		t,
+		"scenario/error_reason.json",
+		responseCompareFuncMap,
+	)
+}
+
+func TestScenarioErrorReasonMismatch(t *testing.T) {
+	assert := assert.New(t)
+	testClient, _, _ := startSampleServer(t)
+	var failures []string
+	reporter := &recordingReporter{failures: &failures}
+	testClient.RunScenario(reporter, "scenario/error_reason_mismatch.json", nil)
+	assert.Equal([]string{
+		"scenario/error_reason_mismatch.json: the test case 0: the error of the response of GetUser does not have the google.rpc.ErrorInfo of the reason USER_NOT_FOUND. Actual: [USER_BANNED]",
+		"scenario/error_reason_mismatch.json: the test case 1: the error of the response of GetUser does not have the google.rpc.ErrorInfo of the reason USER_NOT_FOUND. Actual: []",
+	}, failures)
+}
@@ -0,0 +1,35 @@
+package examples
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScenarioExpectation(t *testing.T) {
+	testClient, _, _ := startSampleServer(t)
+	testClient.RunGRPCTest(
+		t,
+		"scenario/expectation.json",
+		responseCompareFuncMap,
+	)
+}
+
+func TestScenarioExpectationMismatch(t *testing.T) {
+	assert := assert.New(t)
+	testClient, _, _ := startSampleServer(t)
+	var failures []string
+	reporter := &recordingReporter{failures: &failures}
+	testClient.RunScenario(reporter, "scenario/expectation_mismatch.json", nil)
+	if assert.Len(failures, 3) {
+		lines := strings.Split(failures[0], "\n")
+		if assert.Len(lines, 3) {
+			assert.Equal("scenario/expectation_mismatch.json: the test case 0: the response of the GetUser matched none of the $anyOf:", lines[0])
+			assert.True(strings.HasPrefix(lines[1], "$anyOf[0]: the actual response of the GetUser was not equal to the expected response"))
+			assert.True(strings.HasPrefix(lines[2], "$anyOf[1]: the actual response of the GetUser was not equal to the expected response"))
+		}
+		assert.Equal("scenario/expectation_mismatch.json: the test case 1: the response of the GetUser does not satisfy the $schema: $.tags[1] is developer, which is not one of [admin]", failures[1])
+		assert.Equal("scenario/expectation_mismatch.json: the test case 2: the field res_mgs is not in the response", failures[2])
+	}
+}
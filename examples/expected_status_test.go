@@ -0,0 +1,30 @@
+package examples
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScenarioExpectedStatus(t *testing.T) {
+	testClient, _, _ := startSampleServer(t)
+	testClient.RunGRPCTest(
+		t,
+		"scenario/expected_status.json",
+		responseCompareFuncMap,
+	)
+}
+
+func TestScenarioExpectedStatusMismatch(t *testing.T) {
+	assert := assert.New(t)
+	testClient, _, _ := startSampleServer(t)
+	var failures []string
+	reporter := &recordingReporter{failures: &failures}
+	testClient.RunScenario(reporter, "scenario/expected_status_mismatch.json", nil)
+	// The JSON in the message is not compared because protojson does not promise a stable output.
+	if assert.Len(failures, 1) {
+		assert.True(strings.HasPrefix(failures[0], "scenario/expected_status_mismatch.json: the test case 0: the status of the error of the response of GetUser is not as expected."), failures[0])
+		assert.Contains(failures[0], "type.googleapis.com/GetUserRequest")
+	}
+}
@@ -0,0 +1,34 @@
+package examples
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/yoshd/protoc-gen-stest/examples/pb"
+)
+
+func TestFieldComparers(t *testing.T) {
+	assert := assert.New(t)
+	testClient := pb.NewTestClient(nil)
+	// The profiles are equal if their countries are equal regardless of the case.
+	testClient.FieldComparers = map[string]func(expected, actual proto.Message) error{
+		"Profile": func(expected, actual proto.Message) error {
+			if !strings.EqualFold(expected.(*pb.Profile).Country, actual.(*pb.Profile).Country) {
+				return errors.New("the countries are different")
+			}
+			return nil
+		},
+	}
+	actual := &pb.User{Id: "yoshd", Profile: &pb.Profile{Bio: "Yoshi!", Country: "JP"}}
+	assert.NoError(testClient.CompareGetUser(&pb.User{Id: "yoshd", Profile: &pb.Profile{Country: "jp"}}, actual, nil))
+	assert.EqualError(
+		testClient.CompareGetUser(&pb.User{Id: "yoshd", Profile: &pb.Profile{Country: "US"}}, actual, nil),
+		"the actual response of the GetUser was not equal to the expected response: the field profile: the countries are different",
+	)
+	assert.Error(testClient.CompareGetUser(&pb.User{Id: "noissefnoc", Profile: &pb.Profile{Country: "jp"}}, actual, nil))
+	assert.Equal("Yoshi!", actual.Profile.Bio)
+}
@@ -1,45 +1,65 @@
-package main
-
-import (
-	"flag"
-	"net"
-
-	"golang.org/x/net/context"
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
-
-	"github.com/yoshd/protoc-gen-stest/examples/pb"
-)
-
-var (
-	addr = flag.String("addr", "localhost:13009", "addr host:port")
-)
-
-type server struct{}
-
-func (s *server) Hello(ctx context.Context, in *pb.HelloRequest) (*pb.HelloResponse, error) {
-	return &pb.HelloResponse{ResMsg: "Hello!"}, nil
-}
-
-func (s *server) Bye(ctx context.Context, in *pb.ByeRequest) (*pb.ByeResponse, error) {
-	if in.ReqMsg == "error" {
-		return nil, status.Errorf(codes.InvalidArgument, "invalid argument")
-	}
-	return &pb.ByeResponse{ResMsg: "Bye!"}, nil
-}
-
-func main() {
-	flag.Parse()
-
-	lis, err := net.Listen("tcp", *addr)
-
-	if err != nil {
-		panic(err)
-	}
-
-	s := grpc.NewServer()
-
-	pb.RegisterSampleServer(s, &server{})
-	s.Serve(lis)
-}
+package main
+
+import (
+	"flag"
+	"net"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/yoshd/protoc-gen-stest/examples/pb"
+)
+
+var (
+	addr = flag.String("addr", "localhost:13009", "addr host:port")
+)
+
+type server struct{}
+
+func (s *server) Hello(ctx context.Context, in *pb.HelloRequest) (*pb.HelloResponse, error) {
+	return &pb.HelloResponse{ResMsg: "Hello!"}, nil
+}
+
+func (s *server) Bye(ctx context.Context, in *pb.ByeRequest) (*pb.ByeResponse, error) {
+	if in.ReqMsg == "error" {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid argument")
+	}
+	return &pb.ByeResponse{ResMsg: "Bye!"}, nil
+}
+
+func (s *server) GetUser(ctx context.Context, in *pb.GetUserRequest) (*pb.User, error) {
+	switch in.Id {
+	case "":
+		return nil, status.Errorf(codes.InvalidArgument, "id is required")
+	case "unknown":
+		return nil, status.Errorf(codes.NotFound, "user %s is not found", in.Id)
+	case "slow":
+		<-ctx.Done()
+		return nil, status.FromContextError(ctx.Err()).Err()
+	}
+	return &pb.User{
+		Id:         in.Id,
+		Name:       "Yoshi",
+		LoginCount: 3,
+		Tags:       []string{"admin", "developer"},
+		Attributes: map[string]string{"team": "stest", "language": "go"},
+		Profile:    &pb.Profile{Bio: "Yoshi!", Country: "JP"},
+	}, nil
+}
+
+func main() {
+	flag.Parse()
+
+	lis, err := net.Listen("tcp", *addr)
+
+	if err != nil {
+		panic(err)
+	}
+
+	s := grpc.NewServer()
+
+	pb.RegisterSampleServer(s, &server{})
+	s.Serve(lis)
+}
@@ -0,0 +1,34 @@
+package examples
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestScenarioRequireHealthy(t *testing.T) {
+	testClient, _, _ := startSampleServer(t)
+	testClient.RunGRPCTest(
+		t,
+		"scenario/health.json",
+		responseCompareFuncMap,
+	)
+}
+
+func TestWaitHealthy(t *testing.T) {
+	assert := assert.New(t)
+	testClient, _, healthServer := startSampleServer(t)
+
+	healthServer.SetServingStatus("", grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+	assert.Error(testClient.WaitHealthy(context.Background(), 500*time.Millisecond))
+
+	go func() {
+		time.Sleep(300 * time.Millisecond)
+		healthServer.SetServingStatus("", grpc_health_v1.HealthCheckResponse_SERVING)
+	}()
+	assert.NoError(testClient.WaitHealthy(context.Background(), 5*time.Second))
+}
@@ -0,0 +1,58 @@
+package examples
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yoshd/protoc-gen-stest/examples/pb"
+)
+
+func TestScenarioInclude(t *testing.T) {
+	assert := assert.New(t)
+	testClient, im, _ := startSampleServer(t)
+	testClient.RunGRPCTest(
+		t,
+		"scenario/index.json",
+		responseCompareFuncMap,
+	)
+
+	// The test cases of default_metadata.json run after the ones of metadata.json.
+	assert.Equal([]string{"yoshd"}, im.get("/Sample/Bye", "x-tenant"))
+	assert.Empty(im.get("/Sample/Bye", "authorization"))
+}
+
+func TestScenarioIncludeMismatch(t *testing.T) {
+	assert := assert.New(t)
+	testClient, _, _ := startSampleServer(t)
+	var failures []string
+	reporter := &recordingReporter{failures: &failures}
+	testClient.RunScenario(reporter, "scenario/include_mismatch.json", nil)
+	assert.Equal([]string{
+		"scenario/matcher_mismatch.json: the test case 0: the field tags[1] of the response of the GetUser was developer, which does not satisfy $regex ^admin$",
+		"scenario/matcher_mismatch.json: the test case 1: the $all of the field name is invalid: it applies a matcher to the elements of a repeated field",
+	}, failures)
+}
+
+func TestScenarioCyclicInclude(t *testing.T) {
+	assert := assert.New(t)
+	testClient, _, _ := startSampleServer(t)
+	assert.Panics(func() {
+		testClient.RunGRPCTest(
+			t,
+			"scenario/cycle/a.json",
+			responseCompareFuncMap,
+		)
+	})
+}
+
+func TestScenarioInvalidInclude(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal([]error{
+		errors.New("Scenario JSON is invalid. Because the element 1 of the include of scenario/include_invalid.json is not a path: 1"),
+	}, pb.SampleLintScenario("scenario/include_invalid.json"))
+	assert.Equal([]error{
+		errors.New("Scenario JSON is invalid. Because the include of scenario/include_not_array.json is not an array."),
+	}, pb.SampleLintScenario("scenario/include_not_array.json"))
+}
@@ -0,0 +1,59 @@
+package examples
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yoshd/protoc-gen-stest/examples/pb"
+)
+
+func TestLintScenarios(t *testing.T) {
+	assert := assert.New(t)
+	paths, err := filepath.Glob("scenario/*.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	invalid := map[string]bool{
+		"scenario/lint.json":                        true,
+		"scenario/unknown_field.json":               true,
+		"scenario/template_missing.json":            true,
+		"scenario/repeated_response_failure.json":   true,
+		"scenario/response_format_failure.json":     true,
+		"scenario/action_counts_invalid.json":       true,
+		"scenario/include_invalid.json":             true,
+		"scenario/include_not_array.json":           true,
+		"scenario/default_metadata_invalid.json":    true,
+		"scenario/default_metadata_not_object.json": true,
+	}
+	for _, path := range paths {
+		if !invalid[path] {
+			assert.Empty(pb.SampleLintScenario(path), path)
+		}
+	}
+}
+
+func TestLintScenarioProblems(t *testing.T) {
+	assert := assert.New(t)
+	var problems []string
+	for _, err := range pb.SampleLintScenario("scenario/lint.json") {
+		problems = append(problems, err.Error())
+	}
+	// The User having google.protobuf.Any is unmarshaled by protojson, which varies the space after "proto:" of its errors.
+	if assert.Len(problems, 7) {
+		assert.True(strings.HasPrefix(problems[5], "the test case 4: the expected_response is not a response: proto:"), problems[5])
+		assert.Contains(problems[5], `invalid value for int32 type: "three"`)
+		problems[5] = "the test case 4: the expected_response is not a response"
+	}
+	assert.Equal([]string{
+		"the test case 0: expcted_response is unknown",
+		"the test case 0: the request is not a request of Hello: json: cannot unmarshal number into Go struct field HelloRequest.req_msg of type string",
+		"the test case 1: Greet is not a method of the Sample service",
+		"the test case 2: the expected_error_code 17 is not a gRPC error code",
+		"the test case 3: the $anyOf of the expected_response is not an array",
+		"the test case 4: the expected_response is not a response",
+		"the test case 5: the expected_trailer x-retry-count of the assertion 0 is not an integer",
+	}, problems)
+}
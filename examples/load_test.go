@@ -0,0 +1,21 @@
+package examples
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+)
+
+func TestRunGRPCLoad(t *testing.T) {
+	assert := assert.New(t)
+	testClient, _, _ := startSampleServer(t)
+	result := testClient.RunGRPCLoad(t, "scenario/user.json", 4, 200*time.Millisecond)
+
+	assert.NotZero(result.Calls)
+	assert.Zero(result.Errors)
+	assert.NotZero(result.Throughput())
+	stats := testClient.CallStats()
+	assert.Equal(result.Calls, stats["GetUser"][codes.OK]+stats["GetUser"][codes.NotFound])
+}
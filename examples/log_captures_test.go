@@ -0,0 +1,27 @@
+package examples
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScenarioLogCaptures(t *testing.T) {
+	assert := assert.New(t)
+	testClient, _, _ := startSampleServer(t)
+	var failures, logs []string
+	reporter := &recordingReporter{failures: &failures, logs: &logs}
+	testClient.RunScenario(reporter, "scenario/metadata.json", nil)
+	assert.Empty(failures)
+	assert.Equal([]string{"captured token: Hello!"}, logs)
+
+	logs = nil
+	testClient.RedactVariable = func(name string, value interface{}) interface{} {
+		if name == "token" {
+			return "***"
+		}
+		return value
+	}
+	testClient.RunScenario(reporter, "scenario/metadata.json", nil)
+	assert.Equal([]string{"captured token: ***"}, logs)
+}
@@ -0,0 +1,38 @@
+package examples
+
+import (
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/yoshd/protoc-gen-stest/examples/pb"
+)
+
+func TestScenarioMap(t *testing.T) {
+	testClient, _, _ := startSampleServer(t)
+	testClient.RunGRPCTest(
+		t,
+		"scenario/map.json",
+		nil,
+	)
+}
+
+func TestCompareGetUserMap(t *testing.T) {
+	var tester pb.SampleTester = pb.NewTestClient(nil)
+	expected := &pb.User{Id: "yoshd", Attributes: map[string]string{"team": "stest", "language": "go"}}
+	data, err := proto.Marshal(&pb.User{Id: "yoshd", Attributes: map[string]string{"language": "go", "team": "stest"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var actual pb.User
+	if err := proto.Unmarshal(data, &actual); err != nil {
+		t.Fatal(err)
+	}
+	if err := tester.CompareGetUser(expected, &actual, nil); err != nil {
+		t.Errorf("the same responses were not equal: %v", err)
+	}
+	actual.Attributes["team"] = "other"
+	if err := tester.CompareGetUser(expected, &actual, nil); err == nil {
+		t.Error("the different responses were equal")
+	}
+}
@@ -0,0 +1,28 @@
+package examples
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScenarioMatcher(t *testing.T) {
+	testClient, _, _ := startSampleServer(t)
+	testClient.RunGRPCTest(
+		t,
+		"scenario/matcher.json",
+		responseCompareFuncMap,
+	)
+}
+
+func TestScenarioMatcherMismatch(t *testing.T) {
+	assert := assert.New(t)
+	testClient, _, _ := startSampleServer(t)
+	var failures []string
+	reporter := &recordingReporter{failures: &failures}
+	testClient.RunScenario(reporter, "scenario/matcher_mismatch.json", nil)
+	assert.Equal([]string{
+		"scenario/matcher_mismatch.json: the test case 0: the field tags[1] of the response of the GetUser was developer, which does not satisfy $regex ^admin$",
+		"scenario/matcher_mismatch.json: the test case 1: the $all of the field name is invalid: it applies a matcher to the elements of a repeated field",
+	}, failures)
+}
@@ -0,0 +1,22 @@
+package examples
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScenarioMatrix(t *testing.T) {
+	assert := assert.New(t)
+	testClient, _, _ := startSampleServer(t)
+	var failures, names []string
+	reporter := &recordingReporter{failures: &failures, names: &names}
+	testClient.RunScenario(reporter, "scenario/matrix.json", responseCompareFuncMap)
+	assert.Empty(failures)
+	assert.Equal([]string{
+		"GetUser(id=yoshd,login_count=3)",
+		"GetUser(id=noissefnoc,login_count=3)",
+		"Hello Hello",
+		"Hello Yoshi",
+	}, names)
+}
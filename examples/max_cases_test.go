@@ -0,0 +1,31 @@
+package examples
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yoshd/protoc-gen-stest/examples/pb"
+)
+
+func TestScenarioMaxCases(t *testing.T) {
+	assert := assert.New(t)
+	testClient, _, _ := startSampleServer(t)
+	os.Setenv(pb.MaxCasesEnvKey, "1")
+	defer os.Unsetenv(pb.MaxCasesEnvKey)
+	// The second test case, which would also fail, does not run.
+	var failures []string
+	reporter := &recordingReporter{failures: &failures}
+	testClient.RunScenario(reporter, "scenario/error_reason_mismatch.json", nil)
+	assert.Len(failures, 1)
+	assert.Equal(map[string]int{"GetUser": 1}, testClient.ActionCounts())
+
+	os.Setenv(pb.MaxCasesEnvKey, "one")
+	failures = nil
+	reporter.Run("invalid", func(t pb.SampleReporter) {
+		testClient.RunScenario(t, "scenario/user.json", nil)
+	})
+	assert.Equal([]string{"STEST_MAX_CASES must be a non-negative integer, but it is \"one\""}, failures)
+	assert.Equal(map[string]int{"GetUser": 1}, testClient.ActionCounts())
+}
@@ -0,0 +1,70 @@
+package examples
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yoshd/protoc-gen-stest/examples/pb"
+)
+
+func TestScenarioMetadata(t *testing.T) {
+	assert := assert.New(t)
+	testClient, im, _ := startSampleServer(t)
+	testClient.RunGRPCTest(
+		t,
+		"scenario/metadata.json",
+		responseCompareFuncMap,
+	)
+
+	assert.Empty(im.get("/Sample/Hello", "authorization"))
+	assert.Equal([]string{"Bearer Hello!"}, im.get("/Sample/Bye", "authorization"))
+}
+
+func TestScenarioDefaultMetadata(t *testing.T) {
+	assert := assert.New(t)
+	testClient, im, _ := startSampleServer(t)
+	testClient.RunGRPCTest(
+		t,
+		"scenario/default_metadata.json",
+		responseCompareFuncMap,
+	)
+
+	assert.Equal([]string{"yoshd"}, im.get("/Sample/Hello", "x-tenant"))
+	assert.Equal([]string{"1"}, im.get("/Sample/Hello", "x-api-version"))
+	assert.Equal([]string{"yoshd"}, im.get("/Sample/Bye", "x-tenant"))
+	assert.Equal([]string{"2"}, im.get("/Sample/Bye", "x-api-version"))
+}
+
+func TestScenarioInvalidDefaultMetadata(t *testing.T) {
+	assert := assert.New(t)
+	var problems []string
+	for _, path := range []string{"scenario/default_metadata_not_object.json", "scenario/default_metadata_invalid.json"} {
+		for _, err := range pb.SampleLintScenario(path) {
+			problems = append(problems, err.Error())
+		}
+	}
+	assert.Equal([]string{
+		"Scenario JSON is invalid. Because the default_metadata of scenario/default_metadata_not_object.json is not an object.",
+		"Scenario JSON is invalid. Because the default_metadata x-api-version of scenario/default_metadata_invalid.json is not a string.",
+	}, problems)
+}
+
+func TestScenarioTraceparent(t *testing.T) {
+	assert := assert.New(t)
+	os.Setenv(pb.TraceEnvKey, "1")
+	defer os.Unsetenv(pb.TraceEnvKey)
+	testClient, im, _ := startSampleServer(t)
+	testClient.RunGRPCTest(
+		t,
+		"scenario/metadata.json",
+		responseCompareFuncMap,
+	)
+
+	hello := im.get("/Sample/Hello", "traceparent")
+	bye := im.get("/Sample/Bye", "traceparent")
+	if assert.Len(hello, 1) && assert.Len(bye, 1) {
+		assert.Regexp("^00-[0-9a-f]{32}-[0-9a-f]{16}-01$", hello[0])
+		assert.NotEqual(hello[0], bye[0])
+	}
+}
@@ -0,0 +1,26 @@
+package examples
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScenarioPanic(t *testing.T) {
+	assert := assert.New(t)
+	testClient, _, _ := startSampleServer(t)
+	helloResponseCompareFunc := func(expectedResponse, response interface{}) error {
+		panic("the compare function is broken")
+	}
+	compareFuncMap := map[string]*func(expectedResponse, response interface{}) error{
+		"Hello": &helloResponseCompareFunc,
+	}
+	var failures []string
+	reporter := &recordingReporter{failures: &failures}
+	testClient.RunScenario(reporter, "scenario/panic.json", compareFuncMap)
+	if assert.Len(failures, 1) {
+		assert.True(strings.HasPrefix(failures[0], "scenario/panic.json: the test case 0: the test case panicked: the compare function is broken\n"))
+		assert.Contains(failures[0], "recoverCase")
+	}
+}
@@ -0,0 +1,19 @@
+package examples
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScenarioParallelDependent(t *testing.T) {
+	assert := assert.New(t)
+	testClient, _, _ := startSampleServer(t)
+	var failures []string
+	reporter := &recordingReporter{failures: &failures}
+	testClient.RunScenario(reporter, "scenario/parallel_dependent.json", nil)
+	assert.Equal([]string{
+		"scenario/parallel_dependent.json: the test case 0: the test case can not run in parallel because it has ${uuid}, which is generated from STEST_SEED in the order of the test cases",
+		"scenario/parallel_dependent.json: the test case 1: the test case can not run in parallel because it refers to the captured variable ${greeting}",
+	}, failures)
+}
@@ -0,0 +1,155 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.24.0
+// 	protoc        (unknown)
+// source: legacy.proto
+
+package pb
+
+import (
+	proto "github.com/golang/protobuf/proto"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// This is a compile-time assertion that a sufficiently up-to-date version
+// of the legacy proto package is being used.
+const _ = proto.ProtoPackageIsVersion4
+
+// Legacy is the message of proto2, whose optional fields have the presence.
+type Legacy struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Nickname *string `protobuf:"bytes,1,opt,name=nickname" json:"nickname,omitempty"`
+	Level    *int32  `protobuf:"varint,2,opt,name=level" json:"level,omitempty"`
+}
+
+func (x *Legacy) Reset() {
+	*x = Legacy{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_legacy_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Legacy) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Legacy) ProtoMessage() {}
+
+func (x *Legacy) ProtoReflect() protoreflect.Message {
+	mi := &file_legacy_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Legacy.ProtoReflect.Descriptor instead.
+func (*Legacy) Descriptor() ([]byte, []int) {
+	return file_legacy_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Legacy) GetNickname() string {
+	if x != nil && x.Nickname != nil {
+		return *x.Nickname
+	}
+	return ""
+}
+
+func (x *Legacy) GetLevel() int32 {
+	if x != nil && x.Level != nil {
+		return *x.Level
+	}
+	return 0
+}
+
+var File_legacy_proto protoreflect.FileDescriptor
+
+var file_legacy_proto_rawDesc = []byte{
+	0x0a, 0x0c, 0x6c, 0x65, 0x67, 0x61, 0x63, 0x79, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x3a,
+	0x0a, 0x06, 0x4c, 0x65, 0x67, 0x61, 0x63, 0x79, 0x12, 0x1a, 0x0a, 0x08, 0x6e, 0x69, 0x63, 0x6b,
+	0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x6e, 0x69, 0x63, 0x6b,
+	0x6e, 0x61, 0x6d, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x65, 0x76, 0x65, 0x6c, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x05, 0x52, 0x05, 0x6c, 0x65, 0x76, 0x65, 0x6c, 0x42, 0x04, 0x5a, 0x02, 0x70, 0x62,
+}
+
+var (
+	file_legacy_proto_rawDescOnce sync.Once
+	file_legacy_proto_rawDescData = file_legacy_proto_rawDesc
+)
+
+func file_legacy_proto_rawDescGZIP() []byte {
+	file_legacy_proto_rawDescOnce.Do(func() {
+		file_legacy_proto_rawDescData = protoimpl.X.CompressGZIP(file_legacy_proto_rawDescData)
+	})
+	return file_legacy_proto_rawDescData
+}
+
+var file_legacy_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_legacy_proto_goTypes = []interface{}{
+	(*Legacy)(nil), // 0: Legacy
+}
+var file_legacy_proto_depIdxs = []int32{
+	0, // [0:0] is the sub-list for method output_type
+	0, // [0:0] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_legacy_proto_init() }
+func file_legacy_proto_init() {
+	if File_legacy_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_legacy_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Legacy); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_legacy_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   1,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_legacy_proto_goTypes,
+		DependencyIndexes: file_legacy_proto_depIdxs,
+		MessageInfos:      file_legacy_proto_msgTypes,
+	}.Build()
+	File_legacy_proto = out.File
+	file_legacy_proto_rawDesc = nil
+	file_legacy_proto_goTypes = nil
+	file_legacy_proto_depIdxs = nil
+}
@@ -0,0 +1,122 @@
+
+// The command runs the scenario of the Sample service against the server in the same way as RunGRPCTest,
+// and exits with a non-zero status if a test case fails.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
+	pb "github.com/yoshd/protoc-gen-stest/examples/pb"
+)
+
+// reporter is the pb.SampleReporter printing the results of the test cases in the format of go test.
+// The parallel test cases run sequentially.
+type reporter struct {
+	name    string
+	depth   int
+	verbose bool
+	failed  bool
+	skipped bool
+	output  []string
+}
+
+func (r *reporter) Run(name string, f func(t pb.SampleReporter)) bool {
+	sub := &reporter{name: name, depth: r.depth + 1, verbose: r.verbose}
+	if r.depth >= 0 {
+		sub.name = r.name + "/" + name
+	}
+	start := time.Now()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		f(sub)
+	}()
+	<-done
+	result := "PASS"
+	if sub.failed {
+		result = "FAIL"
+		r.failed = true
+	} else if sub.skipped {
+		result = "SKIP"
+	}
+	if sub.failed || r.verbose {
+		indent := strings.Repeat("    ", sub.depth)
+		r.output = append(r.output, fmt.Sprintf("%s--- %s: %s (%.2fs)", indent, result, sub.name, time.Since(start).Seconds()))
+		r.output = append(r.output, sub.output...)
+	}
+	return !sub.failed
+}
+
+func (r *reporter) Fatalf(format string, args ...interface{}) {
+	r.Errorf(format, args...)
+	runtime.Goexit()
+}
+
+func (r *reporter) Errorf(format string, args ...interface{}) {
+	r.Logf(format, args...)
+	r.failed = true
+}
+
+func (r *reporter) Skip(args ...interface{}) {
+	r.Logf("%s", fmt.Sprint(args...))
+	r.skipped = true
+	runtime.Goexit()
+}
+
+func (r *reporter) Logf(format string, args ...interface{}) {
+	indent := strings.Repeat("    ", r.depth+1)
+	for _, line := range strings.Split(strings.TrimSuffix(fmt.Sprintf(format, args...), "\n"), "\n") {
+		r.output = append(r.output, indent+line)
+	}
+}
+
+func (r *reporter) Parallel() {}
+
+func (r *reporter) Failed() bool {
+	return r.failed
+}
+
+func main() {
+	target := flag.String("target", "", "the address of the server such as localhost:50051")
+	scenario := flag.String("scenario", "", "the path of the scenario file")
+	useTLS := flag.Bool("tls", false, "connect to the server with TLS")
+	verbose := flag.Bool("v", false, "print the results of all the test cases")
+	flag.Parse()
+	if *target == "" || *scenario == "" {
+		fmt.Fprintln(os.Stderr, "-target and -scenario are required")
+		flag.Usage()
+		os.Exit(2)
+	}
+	dialOption := grpc.WithInsecure()
+	if *useTLS {
+		dialOption = grpc.WithTransportCredentials(credentials.NewClientTLSFromCert(nil, ""))
+	}
+	runner, err := pb.NewTestClientForTarget(*target, dialOption)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	defer runner.Close()
+
+	top := &reporter{depth: -1, verbose: *verbose}
+	top.Run("Sample", func(t pb.SampleReporter) {
+		runner.RunScenario(t, *scenario, nil)
+	})
+	for _, line := range top.output {
+		fmt.Println(line)
+	}
+	if top.failed {
+		fmt.Println("FAIL")
+		runner.Close()
+		os.Exit(1)
+	}
+	fmt.Println("PASS")
+}
@@ -0,0 +1,12 @@
+package pb
+
+import "errors"
+
+// Validate checks the request in the same way as the method generated by protoc-gen-validate,
+// so that the examples can test validate_request.
+func (m *GetUserRequest) Validate() error {
+	if m.GetId() == "" {
+		return errors.New("invalid GetUserRequest.Id: value length must be at least 1 runes")
+	}
+	return nil
+}
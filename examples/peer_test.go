@@ -0,0 +1,27 @@
+package examples
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScenarioPeer(t *testing.T) {
+	testClient, _, _ := startSampleServer(t)
+	testClient.RunGRPCTest(
+		t,
+		"scenario/peer.json",
+		nil,
+	)
+}
+
+func TestScenarioPeerMismatch(t *testing.T) {
+	assert := assert.New(t)
+	testClient, _, _ := startSampleServer(t)
+	var failures []string
+	reporter := &recordingReporter{failures: &failures}
+	testClient.RunScenario(reporter, "scenario/peer_mismatch.json", nil)
+	assert.Equal([]string{
+		`scenario/peer_mismatch.json: the test case 0: the call of Hello was handled by the peer bufconn, which does not match "^10\\.0\\.0\\.[0-9]+:50051$"`,
+	}, failures)
+}
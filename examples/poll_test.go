@@ -0,0 +1,45 @@
+package examples
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yoshd/protoc-gen-stest/examples/pb"
+)
+
+func TestScenarioPoll(t *testing.T) {
+	assert := assert.New(t)
+	testClient, _, _ := startSampleServer(t)
+	testClient.RunGRPCTest(
+		t,
+		"scenario/poll.json",
+		nil,
+	)
+	assert.Equal(map[string]int{"GetUser": 3}, testClient.ActionCounts())
+}
+
+func TestScenarioPollTimeout(t *testing.T) {
+	assert := assert.New(t)
+	testClient, _, _ := startSampleServer(t)
+	var failures []string
+	reporter := &recordingReporter{failures: &failures}
+	testClient.RunScenario(reporter, "scenario/poll_failure.json", nil)
+	if assert.Len(failures, 1) {
+		assert.True(strings.HasPrefix(failures[0], "scenario/poll_failure.json: the test case 0: "), failures[0])
+		assert.Contains(failures[0], "the GetUser did not succeed within the poll timeout 50ms")
+	}
+}
+
+func TestPollUntilGetUser(t *testing.T) {
+	assert := assert.New(t)
+	testClient, _, _ := startSampleServer(t)
+	predicate := func(res *pb.User, err error) bool {
+		return err == nil && res.Name == "Yoshi"
+	}
+	res := testClient.PollUntilGetUser(context.Background(), t, &pb.GetUserRequest{Id: "eventual"}, predicate, 2*time.Second, 10*time.Millisecond)
+	assert.Equal("eventual", res.Id)
+	assert.Equal(map[string]int{"GetUser": 3}, testClient.ActionCounts())
+}
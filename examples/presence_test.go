@@ -0,0 +1,41 @@
+package examples
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/yoshd/protoc-gen-stest/examples/pb"
+)
+
+func TestScenarioPresence(t *testing.T) {
+	testClient, _, _ := startSampleServer(t)
+	testClient.RunGRPCTest(t, "scenario/presence.json", nil)
+}
+
+func TestScenarioPresenceMismatch(t *testing.T) {
+	assert := assert.New(t)
+	testClient, _, _ := startSampleServer(t)
+	// The optional fields set to the zero values are not equal to the unset fields.
+	var failures []string
+	reporter := &recordingReporter{failures: &failures}
+	testClient.RunScenario(reporter, "scenario/presence_mismatch.json", nil)
+	assert.Equal([]string{
+		"scenario/presence_mismatch.json: the test case 0: the actual response of the GetUser was not equal to the expected response",
+		"scenario/presence_mismatch.json: the test case 1: the actual response of the GetUser was not equal to the expected response",
+		"scenario/presence_mismatch.json: the test case 2: the field age of the response of the GetUser was set to 0, which must be unset",
+	}, failures)
+}
+
+func TestComparePresence(t *testing.T) {
+	testClient := pb.NewTestClient(nil)
+	unset := &pb.User{Legacy: &pb.Legacy{}}
+	zero := &pb.User{Age: proto.Int32(0), Legacy: &pb.Legacy{Level: proto.Int32(0)}}
+	if err := testClient.CompareGetUser(zero, unset, nil); err == nil {
+		t.Error("the optional fields set to the zero values were equal to the unset fields")
+	}
+	if err := testClient.CompareGetUser(zero, proto.Clone(zero).(*pb.User), nil); err != nil {
+		t.Errorf("the same responses were not equal: %v", err)
+	}
+}
@@ -0,0 +1,33 @@
+package examples
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yoshd/protoc-gen-stest/examples/pb"
+)
+
+func TestScenarioRandomTokens(t *testing.T) {
+	assert := assert.New(t)
+	os.Setenv(pb.SeedEnvKey, "42")
+	defer os.Unsetenv(pb.SeedEnvKey)
+	run := func() ([]string, []string) {
+		testClient, im, _ := startSampleServer(t)
+		testClient.RunGRPCTest(
+			t,
+			"scenario/random.json",
+			responseCompareFuncMap,
+		)
+		return im.get("/Sample/Hello", "x-request-id"), im.get("/Sample/Hello", "x-nonce")
+	}
+
+	uuid, nonce := run()
+	if assert.Len(uuid, 1) && assert.Len(nonce, 1) {
+		assert.Regexp("^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$", uuid[0])
+		assert.Regexp("^[0-9]+$", nonce[0])
+	}
+	sameUUID, sameNonce := run()
+	assert.Equal(uuid, sameUUID)
+	assert.Equal(nonce, sameNonce)
+}
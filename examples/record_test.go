@@ -0,0 +1,43 @@
+package examples
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yoshd/protoc-gen-stest/examples/pb"
+)
+
+func TestScenarioRecord(t *testing.T) {
+	assert := assert.New(t)
+	dir, err := ioutil.TempDir("", "stest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	recordPath := filepath.Join(dir, "recorded.json")
+	os.Setenv(pb.RecordEnvKey, recordPath)
+	defer os.Unsetenv(pb.RecordEnvKey)
+	testClient, _, _ := startSampleServer(t)
+	testClient.RunGRPCTest(
+		t,
+		"scenario/record.json",
+		responseCompareFuncMap,
+	)
+
+	recordedData, err := ioutil.ReadFile(recordPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var recorded []map[string]interface{}
+	assert.NoError(json.Unmarshal(recordedData, &recorded))
+	assert.Len(recorded, 3)
+	assert.Equal(map[string]interface{}{"res_msg": "Hello!"}, recorded[0]["expected_response"])
+	assert.Equal("Yoshi", recorded[1]["expected_response"].(map[string]interface{})["name"])
+	assert.Equal(true, recorded[2]["error_expectation"])
+	assert.Equal(float64(5), recorded[2]["expected_error_code"])
+}
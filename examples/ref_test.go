@@ -0,0 +1,14 @@
+package examples
+
+import (
+	"testing"
+)
+
+func TestScenarioResponseReference(t *testing.T) {
+	testClient, _, _ := startSampleServer(t)
+	testClient.RunGRPCTest(
+		t,
+		"scenario/ref.json",
+		responseCompareFuncMap,
+	)
+}
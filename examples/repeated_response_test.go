@@ -0,0 +1,35 @@
+package examples
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yoshd/protoc-gen-stest/examples/pb"
+)
+
+func TestScenarioRepeatedResponse(t *testing.T) {
+	testClient, _, _ := startSampleServer(t)
+	testClient.RunGRPCTest(
+		t,
+		"scenario/repeated_response.json",
+		nil,
+	)
+}
+
+func TestScenarioRepeatedResponseFailure(t *testing.T) {
+	assert := assert.New(t)
+	testClient, _, _ := startSampleServer(t)
+	var failures []string
+	reporter := &recordingReporter{failures: &failures}
+	testClient.RunScenario(reporter, "scenario/repeated_response_failure.json", nil)
+	assert.Equal([]string{
+		"scenario/repeated_response_failure.json: the test case 0: the expected_response is an array, but the HelloResponse has 0 repeated fields instead of exactly one",
+	}, failures)
+	var problems []string
+	for _, err := range pb.SampleLintScenario("scenario/repeated_response_failure.json") {
+		problems = append(problems, err.Error())
+	}
+	assert.Equal([]string{
+		"the test case 0: the expected_response is an array, but the HelloResponse has 0 repeated fields instead of exactly one",
+	}, problems)
+}
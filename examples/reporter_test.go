@@ -0,0 +1,78 @@
+package examples
+
+import (
+	"fmt"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yoshd/protoc-gen-stest/examples/pb"
+)
+
+// recordingReporter records the failures of the test cases instead of failing the test.
+// If names, skips or logs is not nil, the names of the subtests, the reasons of the skips or the logs are also recorded.
+type recordingReporter struct {
+	failures *[]string
+	names    *[]string
+	skips    *[]string
+	logs     *[]string
+	failed   bool
+}
+
+func (r *recordingReporter) Run(name string, f func(t pb.SampleReporter)) bool {
+	if r.names != nil {
+		*r.names = append(*r.names, name)
+	}
+	sub := &recordingReporter{failures: r.failures, names: r.names, skips: r.skips, logs: r.logs}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		f(sub)
+	}()
+	<-done
+	r.failed = r.failed || sub.failed
+	return !sub.failed
+}
+
+func (r *recordingReporter) Fatalf(format string, args ...interface{}) {
+	r.Errorf(format, args...)
+	runtime.Goexit()
+}
+
+func (r *recordingReporter) Errorf(format string, args ...interface{}) {
+	*r.failures = append(*r.failures, fmt.Sprintf(format, args...))
+	r.failed = true
+}
+
+func (r *recordingReporter) Skip(args ...interface{}) {
+	if r.skips != nil {
+		*r.skips = append(*r.skips, fmt.Sprint(args...))
+	}
+	runtime.Goexit()
+}
+
+func (r *recordingReporter) Logf(format string, args ...interface{}) {
+	if r.logs != nil {
+		*r.logs = append(*r.logs, fmt.Sprintf(format, args...))
+	}
+}
+
+func (r *recordingReporter) Parallel() {}
+
+func (r *recordingReporter) Failed() bool {
+	return r.failed
+}
+
+func TestRunScenarioReporter(t *testing.T) {
+	assert := assert.New(t)
+	testClient, _, _ := startSampleServer(t)
+	var failures []string
+	reporter := &recordingReporter{failures: &failures}
+	testClient.RunScenario(reporter, "scenario/request_file.json", nil)
+	assert.False(reporter.Failed())
+
+	testClient.RunScenario(reporter, "scenario/mismatch.json", nil)
+	assert.True(reporter.Failed())
+	assert.Contains(failures, "scenario/mismatch.json: the test case 0: the actual response of the Hello was not equal to the expected response")
+}
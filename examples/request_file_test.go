@@ -0,0 +1,14 @@
+package examples
+
+import (
+	"testing"
+)
+
+func TestScenarioRequestFile(t *testing.T) {
+	testClient, _, _ := startSampleServer(t)
+	testClient.RunGRPCTest(
+		t,
+		"scenario/request_file.json",
+		nil,
+	)
+}
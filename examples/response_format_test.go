@@ -0,0 +1,20 @@
+package examples
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScenarioResponseFormatFailure(t *testing.T) {
+	assert := assert.New(t)
+	testClient, _, _ := startSampleServer(t)
+	var failures []string
+	reporter := &recordingReporter{failures: &failures}
+	testClient.RunScenario(reporter, "scenario/response_format_failure.json", nil)
+	if assert.Len(failures, 2) {
+		assert.True(strings.HasPrefix(failures[0], "scenario/response_format_failure.json: the test case 0: the expected_response of the Hello is not a response in prototext: "), failures[0])
+		assert.Equal("scenario/response_format_failure.json: the test case 1: the response_format yaml is unknown", failures[1])
+	}
+}
@@ -0,0 +1,29 @@
+package examples
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunCase(t *testing.T) {
+	assert := assert.New(t)
+	testClient, _, _ := startSampleServer(t)
+	testClient.RunCase(context.Background(), t, map[string]interface{}{
+		"action": "Hello",
+		"request": map[string]interface{}{
+			"req_msg": "Hello",
+		},
+		"expected_response": map[string]interface{}{
+			"res_msg": "Hello!",
+		},
+	}, responseCompareFuncMap)
+
+	assert.Panics(func() {
+		testClient.RunCase(context.Background(), t, map[string]interface{}{
+			"action":           "Hello",
+			"expcted_response": map[string]interface{}{},
+		}, responseCompareFuncMap)
+	})
+}
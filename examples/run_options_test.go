@@ -0,0 +1,52 @@
+package examples
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yoshd/protoc-gen-stest/examples/pb"
+)
+
+func TestRunOptions(t *testing.T) {
+	testClient, _, _ := startSampleServer(t)
+	testClient.RunGRPCTestWithOptions(t, "scenario/run_options.json", pb.SampleRunOptions{
+		Handlers:     responseCompareFuncMap,
+		IgnoreFields: []string{"login_count"},
+		MatchMode:    "$subset",
+	})
+}
+
+func TestRunOptionsFailures(t *testing.T) {
+	assert := assert.New(t)
+	testClient, _, _ := startSampleServer(t)
+	run := func(options pb.SampleRunOptions) ([]string, []string) {
+		var failures, names []string
+		reporter := &recordingReporter{failures: &failures, names: &names}
+		testClient.RunScenarioWithOptions(reporter, "scenario/run_options.json", options)
+		return failures, names
+	}
+
+	// The login_count is compared without IgnoreFields, and the other fields without the MatchMode.
+	failures, _ := run(pb.SampleRunOptions{MatchMode: "$subset"})
+	assert.Equal([]string{"scenario/run_options.json: the test case 0: the actual response of the GetUser was not equal to the expected response"}, failures)
+	failures, _ = run(pb.SampleRunOptions{IgnoreFields: []string{"login_count"}})
+	assert.Len(failures, 1)
+
+	// The Hello is not called after the GetUser has failed.
+	failures, names := run(pb.SampleRunOptions{StopOnFailure: true})
+	assert.Len(failures, 1)
+	assert.Equal([]string{"GetUser"}, names)
+
+	// The test cases run in the group of the parallel test cases.
+	failures, names = run(pb.SampleRunOptions{Parallel: true, MatchMode: "$subset", IgnoreFields: []string{"login_count"}})
+	assert.Empty(failures)
+	assert.Equal([]string{"parallel", "GetUser", "Hello"}, names)
+
+	var invalid []string
+	reporter := &recordingReporter{failures: &invalid}
+	reporter.Run("invalid", func(t pb.SampleReporter) {
+		testClient.RunScenarioWithOptions(t, "scenario/run_options.json", pb.SampleRunOptions{MatchMode: "$anyOf"})
+	})
+	assert.Equal([]string{"the MatchMode $anyOf is neither $exact nor $subset"}, invalid)
+}
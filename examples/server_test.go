@@ -0,0 +1,137 @@
+package examples
+
+import (
+	"context"
+	"net"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/yoshd/protoc-gen-stest/examples/pb"
+
+	"github.com/golang/protobuf/ptypes"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+type sampleServer struct {
+	// eventualReads is the number of the reads of the user eventual, which is found from the third read as if it had been replicated.
+	eventualReads int32
+}
+
+func (s *sampleServer) Hello(ctx context.Context, in *pb.HelloRequest) (*pb.HelloResponse, error) {
+	grpc.SetHeader(ctx, metadata.Pairs("x-served-by", "sample"))
+	grpc.SetTrailer(ctx, metadata.Pairs("x-retry-count", "2", "x-served-by", "sample"))
+	if deadline, ok := ctx.Deadline(); ok && in.ReqMsg == "deadline" {
+		remaining := time.Until(deadline)
+		return &pb.HelloResponse{ResMsg: "Hello!", RemainingMs: remaining.Milliseconds(), Remaining: ptypes.DurationProto(remaining)}, nil
+	}
+	return &pb.HelloResponse{ResMsg: "Hello!"}, nil
+}
+
+func (s *sampleServer) Bye(ctx context.Context, in *pb.ByeRequest) (*pb.ByeResponse, error) {
+	return &pb.ByeResponse{ResMsg: "Bye!"}, nil
+}
+
+func (s *sampleServer) GetUser(ctx context.Context, in *pb.GetUserRequest) (*pb.User, error) {
+	switch in.Id {
+	case "":
+		st, _ := status.New(codes.InvalidArgument, "id is required").WithDetails(&errdetails.BadRequest{
+			FieldViolations: []*errdetails.BadRequest_FieldViolation{{Field: "id", Description: "id is required"}},
+		})
+		return nil, st.Err()
+	case "unknown":
+		st, _ := status.New(codes.NotFound, "user unknown is not found").WithDetails(&pb.GetUserRequest{Id: in.Id})
+		return nil, st.Err()
+	case "banned":
+		st, _ := status.New(codes.PermissionDenied, "user banned is banned").WithDetails(&errdetails.ErrorInfo{Reason: "USER_BANNED", Domain: "example.com"})
+		return nil, st.Err()
+	case "slow":
+		<-ctx.Done()
+		return nil, status.FromContextError(ctx.Err()).Err()
+	case "dynamic":
+		detail, _ := ptypes.MarshalAny(&pb.Profile{Bio: "Yoshi!", Country: "JP"})
+		extra := &structpb.Struct{Fields: map[string]*structpb.Value{
+			"team":  {Kind: &structpb.Value_StringValue{StringValue: "stest"}},
+			"level": {Kind: &structpb.Value_NumberValue{NumberValue: 3}},
+		}}
+		return &pb.User{Id: in.Id, Detail: detail, Extra: extra}, nil
+	case "eventual":
+		if atomic.AddInt32(&s.eventualReads, 1) < 3 {
+			return nil, status.Error(codes.NotFound, "user eventual is not replicated yet")
+		}
+		return &pb.User{Id: in.Id, Name: "Yoshi"}, nil
+	case "presence":
+		// The optional fields of proto3 and proto2 are set to the zero values, which differs from being unset.
+		return &pb.User{Id: in.Id, Age: proto.Int32(0), Legacy: &pb.Legacy{Nickname: proto.String(""), Level: proto.Int32(0)}}, nil
+	}
+	return &pb.User{
+		Id:         in.Id,
+		Name:       "Yoshi",
+		LoginCount: 3,
+		Tags:       []string{"admin", "developer"},
+		Attributes: map[string]string{"team": "stest", "language": "go"},
+		Profile:    &pb.Profile{Bio: "Yoshi!", Country: "JP"},
+	}, nil
+}
+
+// incomingMetadata records the metadata the server received for each method.
+type incomingMetadata struct {
+	mu sync.Mutex
+	md map[string]metadata.MD
+}
+
+func (im *incomingMetadata) get(method, key string) []string {
+	im.mu.Lock()
+	defer im.mu.Unlock()
+	return im.md[method].Get(key)
+}
+
+// startSampleServer starts the Sample service in process with an interceptor recording the incoming metadata
+// and returns the runner connected to it over bufconn.
+// The returned health server reports SERVING.
+func startSampleServer(t *testing.T) (*pb.SampleTestRunner, *incomingMetadata, *health.Server) {
+	s, im, healthServer := newSampleServer()
+	lis := bufconn.Listen(1024 * 1024)
+	go s.Serve(lis)
+
+	dialer := func(ctx context.Context, target string) (net.Conn, error) {
+		return lis.Dial()
+	}
+	testClient, err := pb.NewTestClientForTarget("bufnet", grpc.WithContextDialer(dialer), grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		testClient.Close()
+		s.Stop()
+	})
+	return testClient, im, healthServer
+}
+
+// newSampleServer returns the gRPC server of the sample service recording the incoming metadata.
+func newSampleServer() (*grpc.Server, *incomingMetadata, *health.Server) {
+	im := &incomingMetadata{md: map[string]metadata.MD{}}
+	interceptor := func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		im.mu.Lock()
+		im.md[info.FullMethod] = md
+		im.mu.Unlock()
+		return handler(ctx, req)
+	}
+
+	s := grpc.NewServer(grpc.UnaryInterceptor(interceptor))
+	pb.RegisterSampleServer(s, &sampleServer{})
+	healthServer := health.NewServer()
+	grpc_health_v1.RegisterHealthServer(s, healthServer)
+	return s, im, healthServer
+}
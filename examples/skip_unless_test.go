@@ -0,0 +1,22 @@
+package examples
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScenarioSkipUnless(t *testing.T) {
+	assert := assert.New(t)
+	testClient, _, _ := startSampleServer(t)
+	// The server is supposed not to support Hello, whose test case would fail.
+	testClient.SkipUnless = func(action string) bool {
+		return action != "Hello"
+	}
+	var failures, skips []string
+	reporter := &recordingReporter{failures: &failures, skips: &skips}
+	testClient.RunScenario(reporter, "scenario/mismatch.json", nil)
+	assert.False(reporter.Failed())
+	assert.Equal([]string{"the Hello is skipped because SkipUnless returned false"}, skips)
+	assert.Zero(testClient.ActionCounts()["Hello"])
+}
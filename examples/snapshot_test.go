@@ -0,0 +1,53 @@
+package examples
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yoshd/protoc-gen-stest/examples/pb"
+)
+
+func TestScenarioSnapshot(t *testing.T) {
+	testClient, _, _ := startSampleServer(t)
+	testClient.SnapshotPath = "snapshot/user.json"
+	testClient.RunGRPCTest(
+		t,
+		"scenario/user.json",
+		responseCompareFuncMap,
+	)
+}
+
+func TestScenarioUpdateSnapshot(t *testing.T) {
+	assert := assert.New(t)
+	dir, err := ioutil.TempDir("", "stest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	testClient, _, _ := startSampleServer(t)
+	testClient.SnapshotPath = filepath.Join(dir, "user.json")
+
+	var failures []string
+	testClient.RunScenario(&recordingReporter{failures: &failures}, "scenario/user.json", responseCompareFuncMap)
+	assert.Equal([]string{"the snapshot " + testClient.SnapshotPath + " does not exist. Run with " + pb.UpdateSnapshotEnvKey + "=1 to write it"}, failures)
+
+	os.Setenv(pb.UpdateSnapshotEnvKey, "1")
+	testClient.RunGRPCTest(t, "scenario/user.json", responseCompareFuncMap)
+	os.Unsetenv(pb.UpdateSnapshotEnvKey)
+	snapshot, err := ioutil.ReadFile(testClient.SnapshotPath)
+	assert.NoError(err)
+	expected, err := ioutil.ReadFile("snapshot/user.json")
+	assert.NoError(err)
+	assert.Equal(strings.Replace(string(expected), "\r\n", "\n", -1), string(snapshot))
+
+	failures = nil
+	testClient.RunScenario(&recordingReporter{failures: &failures}, "scenario/metadata.json", responseCompareFuncMap)
+	if assert.Len(failures, 1) {
+		assert.True(strings.HasPrefix(failures[0], "the outcomes of the calls differ from the snapshot "+testClient.SnapshotPath+" at the line 3."), failures[0])
+	}
+}
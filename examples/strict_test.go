@@ -0,0 +1,26 @@
+package examples
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScenarioUnknownField(t *testing.T) {
+	assert := assert.New(t)
+	testClient, _, _ := startSampleServer(t)
+	assert.Panics(func() {
+		testClient.RunGRPCTest(
+			t,
+			"scenario/unknown_field.json",
+			responseCompareFuncMap,
+		)
+	})
+
+	testClient.AllowUnknownFields = true
+	testClient.RunGRPCTest(
+		t,
+		"scenario/unknown_field.json",
+		responseCompareFuncMap,
+	)
+}
@@ -0,0 +1,28 @@
+package examples
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScenarioTemplate(t *testing.T) {
+	testClient, _, _ := startSampleServer(t)
+	testClient.RunGRPCTest(
+		t,
+		"scenario/template.json",
+		responseCompareFuncMap,
+	)
+}
+
+func TestScenarioMissingTemplate(t *testing.T) {
+	assert := assert.New(t)
+	testClient, _, _ := startSampleServer(t)
+	assert.Panics(func() {
+		testClient.RunGRPCTest(
+			t,
+			"scenario/template_missing.json",
+			responseCompareFuncMap,
+		)
+	})
+}
@@ -0,0 +1,17 @@
+package examples
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScenarioExpectedUnsetFields(t *testing.T) {
+	assert := assert.New(t)
+	testClient, _, _ := startSampleServer(t)
+	var failures []string
+	reporter := &recordingReporter{failures: &failures}
+	testClient.RunScenario(reporter, "scenario/unset.json", nil)
+	assert.True(reporter.Failed())
+	assert.Equal([]string{"scenario/unset.json: the test case 0: the field profile.bio of the response of the GetUser was set to Yoshi!, which must be unset"}, failures)
+}
@@ -0,0 +1,28 @@
+package examples
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScenarioUser(t *testing.T) {
+	testClient, _, _ := startSampleServer(t)
+	testClient.RunGRPCTest(
+		t,
+		"scenario/user.json",
+		nil,
+	)
+}
+
+func TestScenarioUserUnknownField(t *testing.T) {
+	assert := assert.New(t)
+	testClient, _, _ := startSampleServer(t)
+	var failures []string
+	reporter := &recordingReporter{failures: &failures}
+	testClient.RunScenario(reporter, "scenario/user_unknown_field.json", nil)
+	assert.Equal([]string{
+		"scenario/user_unknown_field.json: the test case 0: the field nmae is not in the response",
+		"scenario/user_unknown_field.json: the test case 1: the field profile.contry is not in the response",
+	}, failures)
+}
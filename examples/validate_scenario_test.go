@@ -0,0 +1,27 @@
+package examples
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yoshd/protoc-gen-stest/examples/pb"
+)
+
+func TestScenarioValidateScenario(t *testing.T) {
+	assert := assert.New(t)
+	testClient, _, _ := startSampleServer(t)
+	testClient.AllowUnknownFields = true
+	testClient.ValidateScenario = true
+	var failures []string
+	reporter := &recordingReporter{failures: &failures}
+	// RunScenario is run in a subtest because it fails fatally.
+	reporter.Run("lint", func(t pb.SampleReporter) {
+		testClient.RunScenario(t, "scenario/lint.json", nil)
+	})
+	if assert.Len(failures, 1) {
+		assert.Contains(failures[0], "the scenario scenario/lint.json is invalid:\nthe test case 0: expcted_response is unknown\n")
+		assert.Contains(failures[0], "\nthe test case 1: Greet is not a method of the Sample service\n")
+	}
+	assert.Empty(testClient.ActionCounts())
+}
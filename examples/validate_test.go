@@ -0,0 +1,17 @@
+package examples
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScenarioValidateRequest(t *testing.T) {
+	assert := assert.New(t)
+	testClient, _, _ := startSampleServer(t)
+	var failures []string
+	reporter := &recordingReporter{failures: &failures}
+	testClient.RunScenario(reporter, "scenario/validate.json", responseCompareFuncMap)
+	assert.True(reporter.Failed())
+	assert.Equal([]string{"scenario/validate.json: the test case 1: the request of the GetUser is invalid: invalid GetUserRequest.Id: value length must be at least 1 runes"}, failures)
+}
@@ -0,0 +1,88 @@
+package examples
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+
+	"github.com/yoshd/protoc-gen-stest/examples/pb"
+)
+
+// grpcWebHandler serves the gRPC-Web requests by the gRPC server in the same way as improbable-eng/grpc-web.
+type grpcWebHandler struct {
+	server *grpc.Server
+}
+
+func (h *grpcWebHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	r.ProtoMajor, r.ProtoMinor, r.Proto = 2, 0, "HTTP/2.0"
+	r.Header.Set("Content-Type", "application/grpc+proto")
+	r.Header.Del("X-Grpc-Web")
+	recorder := httptest.NewRecorder()
+	h.server.ServeHTTP(recorder, r)
+
+	var trailer bytes.Buffer
+	for key, values := range recorder.Result().Trailer {
+		for _, v := range values {
+			if v != "" {
+				fmt.Fprintf(&trailer, "%s: %s\r\n", strings.ToLower(key), v)
+			}
+		}
+	}
+	frameHeader := make([]byte, 5)
+	frameHeader[0] = 0x80
+	binary.BigEndian.PutUint32(frameHeader[1:], uint32(trailer.Len()))
+
+	for key, values := range recorder.Header() {
+		if key != "Trailer" && !strings.HasPrefix(key, "Grpc-") && !strings.HasPrefix(key, http.TrailerPrefix) {
+			w.Header()[key] = values
+		}
+	}
+	w.Header().Set("Content-Type", "application/grpc-web+proto")
+	w.Write(recorder.Body.Bytes())
+	w.Write(frameHeader)
+	w.Write(trailer.Bytes())
+}
+
+func startSampleWebServer(t *testing.T) (*pb.SampleTestRunner, *incomingMetadata) {
+	s, im, _ := newSampleServer()
+	ts := httptest.NewServer(&grpcWebHandler{server: s})
+	t.Cleanup(func() {
+		ts.Close()
+		s.Stop()
+	})
+	return pb.NewTestClientForWeb(ts.URL, ts.Client()), im
+}
+
+func TestScenarioWeb(t *testing.T) {
+	assert := assert.New(t)
+	testClient, im := startSampleWebServer(t)
+	testClient.RunGRPCTest(
+		t,
+		"scenario/user.json",
+		responseCompareFuncMap,
+	)
+	testClient.RunGRPCTest(
+		t,
+		"scenario/metadata.json",
+		responseCompareFuncMap,
+	)
+	testClient.RunGRPCTest(
+		t,
+		"scenario/deadline.json",
+		responseCompareFuncMap,
+	)
+	testClient.RunGRPCTest(
+		t,
+		"scenario/assertions.json",
+		responseCompareFuncMap,
+	)
+
+	assert.Equal([]string{"Bearer Hello!"}, im.get("/Sample/Bye", "authorization"))
+}
@@ -1,59 +1,200 @@
-package generator
-
-import (
-	"bytes"
-	"errors"
-	"text/template"
-)
-
-// GRPCCodeGenInfo defines the information to be rendered in the template of the GRPC test code.
-type GRPCCodeGenInfo struct {
-	Package         string
-	GRPCServiceName string
-	GRPCMethods     []GRPCMethod
-}
-
-// GRPCMethod defines the method name and the type string of the request and the type string of the response
-type GRPCMethod struct {
-	Name         string
-	RequestType  string
-	ResponseType string
-}
-
-// Validate validates that the field does not contain zero values.
-func (grpcCodeGenInfo *GRPCCodeGenInfo) Validate() error {
-	if grpcCodeGenInfo.Package == "" {
-		return errors.New("GRPCCodeGenInfo.Package is not allowed empty")
-	}
-	if grpcCodeGenInfo.GRPCServiceName == "" {
-		return errors.New("GRPCCodeGenInfo.GRPCServiceName is not allowed empty")
-	}
-	if len(grpcCodeGenInfo.GRPCMethods) == 0 {
-		return errors.New("GRPCCodeGenInfo.GRPCMethods is not allowed empty")
-	}
-	for _, method := range grpcCodeGenInfo.GRPCMethods {
-		if method.Name == "" {
-			return errors.New("GRPCCodeGenInfo.GRPCMethods is not allowed empty element")
-		}
-		if method.RequestType == "" {
-			return errors.New("GRPCCodeGenInfo.GRPCMethods is not allowed empty element")
-		}
-		if method.ResponseType == "" {
-			return errors.New("GRPCCodeGenInfo.GRPCMethods is not allowed empty element")
-		}
-	}
-	return nil
-}
-
-// GenerateGRPCTestCode generates gRPC scenario test code.
-func GenerateGRPCTestCode(grpcCodeGenInfo GRPCCodeGenInfo) (string, error) {
-	if err := grpcCodeGenInfo.Validate(); err != nil {
-		return "", err
-	}
-	templ, _ := template.New(grpcCodeGenInfo.GRPCServiceName).Parse(codeTemplate)
-	buf := bytes.Buffer{}
-	if err := templ.Execute(&buf, grpcCodeGenInfo); err != nil {
-		return "", err
-	}
-	return buf.String(), nil
-}
+package generator
+
+import (
+	"bytes"
+	"errors"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+// GRPCCodeGenInfo defines the information to be rendered in the template of the GRPC test code.
+type GRPCCodeGenInfo struct {
+	Package         string
+	GRPCServiceName string
+	GRPCMethods     []GRPCMethod
+	// Dispatch is the strategy to call the gRPC method of the action. Empty means DispatchSwitch.
+	Dispatch string
+	// BuildTag is the build tag required to build the generated code, such as integration. Empty means no build constraint.
+	BuildTag string
+	// ImportPath is the import path of the package of the generated code, which is required by GenerateCLICode.
+	ImportPath string
+	// DetailImports are the import paths of the packages of the error detail messages other than google.rpc,
+	// which are imported by the generated code so that the details of the error object can be decoded into them.
+	DetailImports []string
+	// MessageTypes are the type strings of the messages defined in the proto files, such as the full names of the messages given by protoc.
+	// If it is not empty, the request type and the response type of each method must be one of them.
+	MessageTypes []string
+	// SplitMethods leaves the code of each gRPC method out of the code generated by GenerateGRPCTestCode,
+	// so that it is generated by GenerateGRPCTestMethodCode in its own file of the same package.
+	SplitMethods bool
+}
+
+const (
+	// DispatchSwitch generates a switch statement and a function for each gRPC method.
+	DispatchSwitch = "switch"
+	// DispatchReflect calls the gRPC methods by reflection, which makes the generated code smaller for large services.
+	DispatchReflect = "reflect"
+)
+
+// GRPCMethod defines the method name and the type string of the request and the type string of the response.
+// The type strings are written verbatim in the generated code, so they can be qualified by a package such as pb.HelloRequest
+// when the generated code is in another package than the messages.
+type GRPCMethod struct {
+	Name         string
+	RequestType  string
+	ResponseType string
+	// Comment is the leading comment of the method in the proto file, which documents the generated code of the method. Empty means none.
+	Comment string
+}
+
+// CommentLines returns the lines of Comment as the lines of a Go comment.
+func (method GRPCMethod) CommentLines() []string {
+	if method.Comment == "" {
+		return nil
+	}
+	lines := strings.Split(strings.TrimRight(method.Comment, "\n"), "\n")
+	for i, line := range lines {
+		line = strings.TrimRight(strings.TrimPrefix(line, " "), " \t")
+		if line == "" {
+			lines[i] = "//"
+			continue
+		}
+		lines[i] = "// " + line
+	}
+	return lines
+}
+
+// typeNamePattern matches a Go type name optionally qualified by a package name.
+var typeNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// buildTagPattern matches a build tag.
+var buildTagPattern = regexp.MustCompile(`^[A-Za-z0-9_.]+$`)
+
+// importPathPattern matches an import path.
+var importPathPattern = regexp.MustCompile(`^[A-Za-z0-9_.~/-]+$`)
+
+// Validate validates that the field does not contain zero values.
+func (grpcCodeGenInfo *GRPCCodeGenInfo) Validate() error {
+	if grpcCodeGenInfo.Package == "" {
+		return errors.New("GRPCCodeGenInfo.Package is not allowed empty")
+	}
+	if grpcCodeGenInfo.GRPCServiceName == "" {
+		return errors.New("GRPCCodeGenInfo.GRPCServiceName is not allowed empty")
+	}
+	if len(grpcCodeGenInfo.GRPCMethods) == 0 {
+		return errors.New("GRPCCodeGenInfo.GRPCMethods is not allowed empty")
+	}
+	if grpcCodeGenInfo.Dispatch != "" && grpcCodeGenInfo.Dispatch != DispatchSwitch && grpcCodeGenInfo.Dispatch != DispatchReflect {
+		return errors.New("GRPCCodeGenInfo.Dispatch must be " + DispatchSwitch + " or " + DispatchReflect)
+	}
+	if grpcCodeGenInfo.BuildTag != "" && !buildTagPattern.MatchString(grpcCodeGenInfo.BuildTag) {
+		return errors.New("GRPCCodeGenInfo.BuildTag has invalid build tag " + grpcCodeGenInfo.BuildTag)
+	}
+	for _, importPath := range grpcCodeGenInfo.DetailImports {
+		if !importPathPattern.MatchString(importPath) {
+			return errors.New("GRPCCodeGenInfo.DetailImports has invalid import path " + importPath)
+		}
+	}
+	messageTypes := make(map[string]bool, len(grpcCodeGenInfo.MessageTypes))
+	for _, messageType := range grpcCodeGenInfo.MessageTypes {
+		messageTypes[messageType] = true
+	}
+	for _, method := range grpcCodeGenInfo.GRPCMethods {
+		if method.Name == "" {
+			return errors.New("GRPCCodeGenInfo.GRPCMethods is not allowed empty element")
+		}
+		if method.RequestType == "" {
+			return errors.New("GRPCCodeGenInfo.GRPCMethods is not allowed empty element")
+		}
+		if method.ResponseType == "" {
+			return errors.New("GRPCCodeGenInfo.GRPCMethods is not allowed empty element")
+		}
+		if !typeNamePattern.MatchString(method.RequestType) {
+			return errors.New("GRPCCodeGenInfo.GRPCMethods has invalid request type " + method.RequestType)
+		}
+		if !typeNamePattern.MatchString(method.ResponseType) {
+			return errors.New("GRPCCodeGenInfo.GRPCMethods has invalid response type " + method.ResponseType)
+		}
+		if len(messageTypes) > 0 && !messageTypes[method.RequestType] {
+			return errors.New("GRPCCodeGenInfo.GRPCMethods has the request type " + method.RequestType + " of " + method.Name + " which is not a message")
+		}
+		if len(messageTypes) > 0 && !messageTypes[method.ResponseType] {
+			return errors.New("GRPCCodeGenInfo.GRPCMethods has the response type " + method.ResponseType + " of " + method.Name + " which is not a message")
+		}
+	}
+	return nil
+}
+
+// GenerateGRPCTestCode generates gRPC scenario test code.
+func GenerateGRPCTestCode(grpcCodeGenInfo GRPCCodeGenInfo) (string, error) {
+	if err := grpcCodeGenInfo.Validate(); err != nil {
+		return "", err
+	}
+	templ := template.Must(template.New(grpcCodeGenInfo.GRPCServiceName).Parse(codeTemplate))
+	templ = template.Must(templ.Parse(methodsTemplate))
+	buf := bytes.Buffer{}
+	if err := templ.Execute(&buf, grpcCodeGenInfo); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// GenerateGRPCTestMethodCode generates the code of the gRPC method named methodName, which is left out of the code
+// generated by GenerateGRPCTestCode if GRPCCodeGenInfo.SplitMethods is true.
+// The generated code of the service and its methods compile to the same runner as the code generated in one file.
+func GenerateGRPCTestMethodCode(grpcCodeGenInfo GRPCCodeGenInfo, methodName string) (string, error) {
+	if err := grpcCodeGenInfo.Validate(); err != nil {
+		return "", err
+	}
+	if !grpcCodeGenInfo.SplitMethods {
+		return "", errors.New("GRPCCodeGenInfo.SplitMethods must be true to generate the code of a method")
+	}
+	methodCodeGenInfo := grpcCodeGenInfo
+	methodCodeGenInfo.GRPCMethods = nil
+	for _, method := range grpcCodeGenInfo.GRPCMethods {
+		if method.Name == methodName {
+			methodCodeGenInfo.GRPCMethods = []GRPCMethod{method}
+		}
+	}
+	if len(methodCodeGenInfo.GRPCMethods) == 0 {
+		return "", errors.New("GRPCCodeGenInfo.GRPCMethods does not have the method " + methodName)
+	}
+	templ := template.Must(template.New(grpcCodeGenInfo.GRPCServiceName + methodName).Parse(methodTemplate))
+	templ = template.Must(templ.Parse(methodsTemplate))
+	buf := bytes.Buffer{}
+	if err := templ.Execute(&buf, methodCodeGenInfo); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// GenerateGinkgoCode generates the code declaring the scenario as the Ginkgo specs by the runner of the gRPC scenario test code,
+// which is in the same package as the runner. The specs share the requests and the assertions with the runner.
+func GenerateGinkgoCode(grpcCodeGenInfo GRPCCodeGenInfo) (string, error) {
+	if err := grpcCodeGenInfo.Validate(); err != nil {
+		return "", err
+	}
+	templ, _ := template.New(grpcCodeGenInfo.GRPCServiceName).Parse(ginkgoTemplate)
+	buf := bytes.Buffer{}
+	if err := templ.Execute(&buf, grpcCodeGenInfo); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// GenerateCLICode generates the code of the main package of the CLI running the scenario with the generated gRPC scenario test code.
+func GenerateCLICode(grpcCodeGenInfo GRPCCodeGenInfo) (string, error) {
+	if err := grpcCodeGenInfo.Validate(); err != nil {
+		return "", err
+	}
+	if grpcCodeGenInfo.ImportPath == "" {
+		return "", errors.New("GRPCCodeGenInfo.ImportPath is not allowed empty")
+	}
+	templ, _ := template.New(grpcCodeGenInfo.GRPCServiceName).Parse(cliTemplate)
+	buf := bytes.Buffer{}
+	if err := templ.Execute(&buf, grpcCodeGenInfo); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
@@ -1,373 +1,4569 @@
-package generator
-
-import (
-	"testing"
-
-	"github.com/stretchr/testify/assert"
-)
-
-func TestGRPCCodeGenInfoValidateNoError(t *testing.T) {
-	assert := assert.New(t)
-	cases := []GRPCCodeGenInfo{
-		{
-			"package",
-			"ServiceName",
-			[]GRPCMethod{
-				{
-					"Method1",
-					"Request",
-					"Response",
-				},
-				{
-					"Method2",
-					"Request",
-					"Response",
-				},
-			},
-		},
-	}
-	for _, c := range cases {
-		err := c.Validate()
-		assert.NoError(err)
-	}
-}
-
-func TestGRPCCodeGenInfoValidateError(t *testing.T) {
-	assert := assert.New(t)
-	cases := []GRPCCodeGenInfo{
-		{
-			"",
-			"ServiceName",
-			[]GRPCMethod{
-				{
-					"Method1",
-					"Request",
-					"Response",
-				},
-				{
-					"Method2",
-					"Request",
-					"Response",
-				},
-			},
-		},
-		{
-			"package",
-			"",
-			[]GRPCMethod{
-				{
-					"Method1",
-					"Request",
-					"Response",
-				},
-				{
-					"Method2",
-					"Request",
-					"Response",
-				},
-			},
-		},
-		{
-			"package",
-			"ServiceName",
-			[]GRPCMethod{},
-		},
-		{
-			"package",
-			"ServiceName",
-			[]GRPCMethod{
-				{
-					"",
-					"Request",
-					"Response",
-				},
-				{
-					"Method2",
-					"Request",
-					"Response",
-				},
-			},
-		},
-		{
-			"package",
-			"ServiceName",
-			[]GRPCMethod{
-				{
-					"Method1",
-					"Request",
-					"Response",
-				},
-				{
-					"Method2",
-					"",
-					"Response",
-				},
-			},
-		},
-		{
-			"package",
-			"ServiceName",
-			[]GRPCMethod{
-				{
-					"Method1",
-					"Request",
-					"Response",
-				},
-				{
-					"Method2",
-					"Request",
-					"",
-				},
-			},
-		},
-	}
-	for _, c := range cases {
-		err := c.Validate()
-		assert.Error(err)
-	}
-}
-
-func TestGenerateGRPCTestCode(t *testing.T) {
-	assert := assert.New(t)
-	grpcCodeGenInfo := GRPCCodeGenInfo{
-		Package:         "pb",
-		GRPCServiceName: "TestService",
-		GRPCMethods: []GRPCMethod{
-			{
-				Name:         "Hello",
-				RequestType:  "HReq",
-				ResponseType: "HRes",
-			},
-			{
-				Name:         "Bye",
-				RequestType:  "BReq",
-				ResponseType: "BRes",
-			},
-		},
-	}
-	code, err := GenerateGRPCTestCode(grpcCodeGenInfo)
-	assert.Equal(expectedCode, code)
-	assert.NoError(err)
-}
-
-var expectedCode = `
-package pb
-
-import (
-	"context"
-	"encoding/json"
-	"errors"
-	"io/ioutil"
-	"reflect"
-	"testing"
-	"time"
-
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/codes"
-)
-
-// TestServiceTestRunner is a runner to run the TestService service test.
-type TestServiceTestRunner struct {
-	Client TestServiceClient
-}
-
-// NewTestClient returns new TestServiceRunner.
-func NewTestClient(client TestServiceClient) *TestServiceTestRunner {
-	return &TestServiceTestRunner{
-		Client: client,
-	}
-}
-
-// RunGRPCTest sends a gPRC request according to the scenario written in the JSON file and tests the response.
-// compareFuncMap takes a gRPC method name as a key and value has a function that compares expected response and actual response and return an error.
-func (runner *TestServiceTestRunner) RunGRPCTest(t *testing.T, jsonPath string, compareFuncMap map[string]*func(expectedResponse, response interface{}) error) {
-	scenarioData, err := ioutil.ReadFile(jsonPath)
-	if err != nil {
-		panic(err)
-	}
-	var scenario []map[string]interface{}
-	json.Unmarshal(scenarioData, &scenario)
-	for _, testCase := range scenario {
-		ctx := context.Background()
-		runner.runTest(ctx, t, testCase, compareFuncMap)
-	}
-}
-
-const (
-	actionJSONKey            = "action"
-	requestJSONKey           = "request"
-	expectedResponseJSONKey  = "expected_response"
-	errorExpectationJSONKey  = "error_expectation"
-	expectedErrorCodeJSONKey = "expected_error_code"
-	loopJSONKey              = "loop"
-	sleepJSONKey             = "sleep"
-	successRuleJSONKey       = "success_rule"
-	successRuleAll           = "all"
-	successRuleOnce          = "once"
-)
-
-func (runner *TestServiceTestRunner) runTest(ctx context.Context, t *testing.T, testCase map[string]interface{}, compareFuncMap map[string]*func(expectedResponse, response interface{}) error) {
-	var action string
-	if v, ok := testCase[actionJSONKey]; ok {
-		action = v.(string)
-	} else {
-		panic("Scenario JSON is invalid. Because action is required.")
-	}
-	f := func(t *testing.T) {
-		switch action {
-		case "Hello":
-			compareFunc := compareFuncMap["Hello"]
-			runner.testHello(ctx, t, testCase, compareFunc)
-		case "Bye":
-			compareFunc := compareFuncMap["Bye"]
-			runner.testBye(ctx, t, testCase, compareFunc)
-		}
-	}
-	t.Run(action, f)
-}
-
-func (runner *TestServiceTestRunner) testHello(ctx context.Context, t *testing.T, testCase map[string]interface{}, compareFunc *func(expectedResponse, response interface{}) error) {
-	reqJSON, reqErr := json.Marshal(testCase[requestJSONKey])
-	if reqErr != nil {
-		panic(reqErr)
-	}
-	req := HReq{}
-	json.Unmarshal(reqJSON, &req)
-
-	loop := 1
-	if v, ok := testCase[loopJSONKey]; ok {
-		loop = int(v.(float64))
-	}
-FOR_LABEL:
-	for i := 1; i <= loop; i++ {
-		sleep := 0
-		if v, ok := testCase[sleepJSONKey]; ok {
-			sleep = int(v.(float64))
-		}
-		time.Sleep(time.Duration(sleep) * time.Second)
-
-		res, err := runner.Client.Hello(ctx, &req)
-
-		errExpectation := false
-		if v, ok := testCase[errorExpectationJSONKey]; ok {
-			errExpectation = v.(bool)
-		}
-		if errExpectation {
-			errCodeF := testCase[expectedErrorCodeJSONKey].(float64)
-			errCodeU := uint32(errCodeF)
-			expectedErrCode := codes.Code(errCodeU)
-			if expectedErrCode != grpc.Code(err) {
-				t.Fatalf("the error code of the response of Hello is not as expected. Expected: %d, Actual: %d\n", expectedErrCode, grpc.Code(err))
-			}
-			break FOR_LABEL
-		} else {
-			resJSON, resErr := json.Marshal(testCase[expectedResponseJSONKey])
-			if resErr != nil {
-				panic(resErr)
-			}
-			expectedRes := HRes{}
-			json.Unmarshal(resJSON, &expectedRes)
-			successRule := successRuleAll
-			if v, ok := testCase[successRuleJSONKey]; ok {
-				successRule = v.(string)
-			}
-			var err error
-			if compareFunc != nil {
-				compare := *compareFunc
-				err = compare(expectedRes, *res)
-			} else {
-				if !reflect.DeepEqual(expectedRes, *res) {
-					err = errors.New("the actual response of the Hello was not equal to the expected response")
-				}
-			}
-
-			switch successRule {
-			case successRuleAll:
-				if err != nil {
-					t.Fatal(err.Error())
-				}
-			case successRuleOnce:
-				if i == loop && err != nil {
-					t.Fatal(err.Error())
-				}
-				if err == nil {
-					break FOR_LABEL
-				}
-			}
-		}
-	}
-}
-
-func (runner *TestServiceTestRunner) testBye(ctx context.Context, t *testing.T, testCase map[string]interface{}, compareFunc *func(expectedResponse, response interface{}) error) {
-	reqJSON, reqErr := json.Marshal(testCase[requestJSONKey])
-	if reqErr != nil {
-		panic(reqErr)
-	}
-	req := BReq{}
-	json.Unmarshal(reqJSON, &req)
-
-	loop := 1
-	if v, ok := testCase[loopJSONKey]; ok {
-		loop = int(v.(float64))
-	}
-FOR_LABEL:
-	for i := 1; i <= loop; i++ {
-		sleep := 0
-		if v, ok := testCase[sleepJSONKey]; ok {
-			sleep = int(v.(float64))
-		}
-		time.Sleep(time.Duration(sleep) * time.Second)
-
-		res, err := runner.Client.Bye(ctx, &req)
-
-		errExpectation := false
-		if v, ok := testCase[errorExpectationJSONKey]; ok {
-			errExpectation = v.(bool)
-		}
-		if errExpectation {
-			errCodeF := testCase[expectedErrorCodeJSONKey].(float64)
-			errCodeU := uint32(errCodeF)
-			expectedErrCode := codes.Code(errCodeU)
-			if expectedErrCode != grpc.Code(err) {
-				t.Fatalf("the error code of the response of Bye is not as expected. Expected: %d, Actual: %d\n", expectedErrCode, grpc.Code(err))
-			}
-			break FOR_LABEL
-		} else {
-			resJSON, resErr := json.Marshal(testCase[expectedResponseJSONKey])
-			if resErr != nil {
-				panic(resErr)
-			}
-			expectedRes := BRes{}
-			json.Unmarshal(resJSON, &expectedRes)
-			successRule := successRuleAll
-			if v, ok := testCase[successRuleJSONKey]; ok {
-				successRule = v.(string)
-			}
-			var err error
-			if compareFunc != nil {
-				compare := *compareFunc
-				err = compare(expectedRes, *res)
-			} else {
-				if !reflect.DeepEqual(expectedRes, *res) {
-					err = errors.New("the actual response of the Bye was not equal to the expected response")
-				}
-			}
-
-			switch successRule {
-			case successRuleAll:
-				if err != nil {
-					t.Fatal(err.Error())
-				}
-			case successRuleOnce:
-				if i == loop && err != nil {
-					t.Fatal(err.Error())
-				}
-				if err == nil {
-					break FOR_LABEL
-				}
-			}
-		}
-	}
-}
-
-`
+package generator
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGRPCCodeGenInfoValidateNoError(t *testing.T) {
+	assert := assert.New(t)
+	cases := []GRPCCodeGenInfo{
+		{
+			Package:         "package",
+			GRPCServiceName: "ServiceName",
+			GRPCMethods: []GRPCMethod{
+				{
+					Name:         "Method1",
+					RequestType:  "Request",
+					ResponseType: "Response",
+				},
+				{
+					Name:         "Method2",
+					RequestType:  "Request",
+					ResponseType: "Response",
+				},
+			},
+		},
+		{
+			Package:         "package",
+			GRPCServiceName: "ServiceName",
+			GRPCMethods: []GRPCMethod{
+				{
+					Name:         "Method1",
+					RequestType:  "Request",
+					ResponseType: "Response",
+				},
+			},
+			Dispatch: DispatchReflect,
+		},
+		{
+			Package:         "package",
+			GRPCServiceName: "ServiceName",
+			GRPCMethods: []GRPCMethod{
+				{
+					Name:         "Method1",
+					RequestType:  "Request",
+					ResponseType: "Response",
+				},
+			},
+			BuildTag: "integration",
+		},
+		{
+			Package:         "runner",
+			GRPCServiceName: "ServiceName",
+			GRPCMethods: []GRPCMethod{
+				{
+					Name:         "Method1",
+					RequestType:  "pb.Request",
+					ResponseType: "pb.Response",
+				},
+			},
+		},
+		{
+			Package:         "package",
+			GRPCServiceName: "ServiceName",
+			GRPCMethods: []GRPCMethod{
+				{
+					Name:         "Method1",
+					RequestType:  "Request",
+					ResponseType: "Response",
+				},
+			},
+			DetailImports: []string{"example.com/errors/v1"},
+		},
+		{
+			Package:         "package",
+			GRPCServiceName: "ServiceName",
+			GRPCMethods: []GRPCMethod{
+				{
+					Name:         "Method1",
+					RequestType:  "Request",
+					ResponseType: "Response",
+				},
+			},
+			MessageTypes: []string{"Request", "Response", "Other"},
+		},
+	}
+	for _, c := range cases {
+		err := c.Validate()
+		assert.NoError(err)
+	}
+}
+
+func TestGRPCCodeGenInfoValidateError(t *testing.T) {
+	assert := assert.New(t)
+	cases := []GRPCCodeGenInfo{
+		{
+			Package:         "",
+			GRPCServiceName: "ServiceName",
+			GRPCMethods: []GRPCMethod{
+				{
+					Name:         "Method1",
+					RequestType:  "Request",
+					ResponseType: "Response",
+				},
+				{
+					Name:         "Method2",
+					RequestType:  "Request",
+					ResponseType: "Response",
+				},
+			},
+		},
+		{
+			Package:         "package",
+			GRPCServiceName: "",
+			GRPCMethods: []GRPCMethod{
+				{
+					Name:         "Method1",
+					RequestType:  "Request",
+					ResponseType: "Response",
+				},
+				{
+					Name:         "Method2",
+					RequestType:  "Request",
+					ResponseType: "Response",
+				},
+			},
+		},
+		{
+			Package:         "package",
+			GRPCServiceName: "ServiceName",
+			GRPCMethods:     []GRPCMethod{},
+		},
+		{
+			Package:         "package",
+			GRPCServiceName: "ServiceName",
+			GRPCMethods: []GRPCMethod{
+				{
+					Name:         "",
+					RequestType:  "Request",
+					ResponseType: "Response",
+				},
+				{
+					Name:         "Method2",
+					RequestType:  "Request",
+					ResponseType: "Response",
+				},
+			},
+		},
+		{
+			Package:         "package",
+			GRPCServiceName: "ServiceName",
+			GRPCMethods: []GRPCMethod{
+				{
+					Name:         "Method1",
+					RequestType:  "Request",
+					ResponseType: "Response",
+				},
+				{
+					Name:         "Method2",
+					RequestType:  "",
+					ResponseType: "Response",
+				},
+			},
+		},
+		{
+			Package:         "package",
+			GRPCServiceName: "ServiceName",
+			GRPCMethods: []GRPCMethod{
+				{
+					Name:         "Method1",
+					RequestType:  "Request",
+					ResponseType: "Response",
+				},
+				{
+					Name:         "Method2",
+					RequestType:  "Request",
+					ResponseType: "",
+				},
+			},
+		},
+		{
+			Package:         "package",
+			GRPCServiceName: "ServiceName",
+			GRPCMethods: []GRPCMethod{
+				{
+					Name:         "Method1",
+					RequestType:  "Request",
+					ResponseType: "Response",
+				},
+			},
+			Dispatch: "map",
+		},
+		{
+			Package:         "package",
+			GRPCServiceName: "ServiceName",
+			GRPCMethods: []GRPCMethod{
+				{
+					Name:         "Method1",
+					RequestType:  "Request",
+					ResponseType: "Response",
+				},
+			},
+			BuildTag: "integration test",
+		},
+		{
+			Package:         "package",
+			GRPCServiceName: "ServiceName",
+			GRPCMethods: []GRPCMethod{
+				{
+					Name:         "Method1",
+					RequestType:  "google.protobuf.Empty",
+					ResponseType: "Response",
+				},
+			},
+		},
+		{
+			Package:         "package",
+			GRPCServiceName: "ServiceName",
+			GRPCMethods: []GRPCMethod{
+				{
+					Name:         "Method1",
+					RequestType:  "Request",
+					ResponseType: "*Response",
+				},
+			},
+		},
+		{
+			Package:         "package",
+			GRPCServiceName: "ServiceName",
+			GRPCMethods: []GRPCMethod{
+				{
+					Name:         "Method1",
+					RequestType:  "Request",
+					ResponseType: "Response",
+				},
+			},
+			DetailImports: []string{"example.com/errors\"v1"},
+		},
+		{
+			Package:         "package",
+			GRPCServiceName: "ServiceName",
+			GRPCMethods: []GRPCMethod{
+				{
+					Name:         "Method1",
+					RequestType:  "Request",
+					ResponseType: "Response",
+				},
+			},
+			MessageTypes: []string{"Request", "OldResponse"},
+		},
+		{
+			Package:         "package",
+			GRPCServiceName: "ServiceName",
+			GRPCMethods: []GRPCMethod{
+				{
+					Name:         "Method1",
+					RequestType:  "RenamedRequest",
+					ResponseType: "Response",
+				},
+			},
+			MessageTypes: []string{"Request", "Response"},
+		},
+	}
+	for _, c := range cases {
+		err := c.Validate()
+		assert.Error(err)
+	}
+}
+
+func TestGenerateGRPCTestCode(t *testing.T) {
+	assert := assert.New(t)
+	grpcCodeGenInfo := GRPCCodeGenInfo{
+		Package:         "pb",
+		GRPCServiceName: "TestService",
+		GRPCMethods: []GRPCMethod{
+			{
+				Name:         "Hello",
+				RequestType:  "HReq",
+				ResponseType: "HRes",
+			},
+			{
+				Name:         "Bye",
+				RequestType:  "BReq",
+				ResponseType: "BRes",
+			},
+		},
+	}
+	code, err := GenerateGRPCTestCode(grpcCodeGenInfo)
+	assert.Equal(expectedCode, code)
+	assert.NoError(err)
+}
+
+func TestGenerateGRPCTestCodeReflectDispatch(t *testing.T) {
+	assert := assert.New(t)
+	grpcCodeGenInfo := GRPCCodeGenInfo{
+		Package:         "pb",
+		GRPCServiceName: "TestService",
+		GRPCMethods: []GRPCMethod{
+			{
+				Name:         "Hello",
+				RequestType:  "HReq",
+				ResponseType: "HRes",
+			},
+		},
+		Dispatch: DispatchReflect,
+	}
+	code, err := GenerateGRPCTestCode(grpcCodeGenInfo)
+	assert.NoError(err)
+	assert.Contains(code, `"Hello": {reflect.TypeOf((*HReq)(nil)).Elem(), reflect.TypeOf((*HRes)(nil)).Elem()},`)
+	assert.Contains(code, "runner.reflectMethod(action)")
+	assert.NotContains(code, "func (runner *TestServiceTestRunner) testHello(")
+	assert.NotContains(code, "switch action {")
+}
+
+func TestGenerateGRPCTestCodeQualifiedTypes(t *testing.T) {
+	assert := assert.New(t)
+	grpcCodeGenInfo := GRPCCodeGenInfo{
+		Package:         "runner",
+		GRPCServiceName: "TestService",
+		GRPCMethods: []GRPCMethod{
+			{
+				Name:         "Hello",
+				RequestType:  "pb.HelloRequest",
+				ResponseType: "pb.HelloResponse",
+			},
+		},
+	}
+	code, err := GenerateGRPCTestCode(grpcCodeGenInfo)
+	assert.NoError(err)
+	assert.Contains(code, "return &pb.HelloRequest{}")
+	assert.Contains(code, "return &pb.HelloResponse{}")
+	assert.Contains(code, "runner.Client.Hello(ctx, req.(*pb.HelloRequest), opts...)")
+}
+
+func TestGenerateGRPCTestCodeBuildTag(t *testing.T) {
+	assert := assert.New(t)
+	grpcCodeGenInfo := GRPCCodeGenInfo{
+		Package:         "pb",
+		GRPCServiceName: "TestService",
+		GRPCMethods: []GRPCMethod{
+			{
+				Name:         "Hello",
+				RequestType:  "HReq",
+				ResponseType: "HRes",
+			},
+		},
+		BuildTag: "integration",
+	}
+	code, err := GenerateGRPCTestCode(grpcCodeGenInfo)
+	assert.NoError(err)
+	assert.True(strings.HasPrefix(code, "\n//go:build integration\n// +build integration\n\npackage pb\n"))
+}
+
+func TestGenerateGRPCTestCodeDetailImports(t *testing.T) {
+	assert := assert.New(t)
+	grpcCodeGenInfo := GRPCCodeGenInfo{
+		Package:         "pb",
+		GRPCServiceName: "TestService",
+		GRPCMethods: []GRPCMethod{
+			{
+				Name:         "Hello",
+				RequestType:  "HReq",
+				ResponseType: "HRes",
+			},
+		},
+		DetailImports: []string{"example.com/errors/v1", "example.com/quota"},
+	}
+	code, err := GenerateGRPCTestCode(grpcCodeGenInfo)
+	assert.NoError(err)
+	assert.Contains(code, "\"google.golang.org/protobuf/testing/protocmp\"\n\n\t_ \"example.com/errors/v1\"\n\t_ \"example.com/quota\"\n)\n")
+}
+
+func TestGenerateGRPCTestCodeMethodComment(t *testing.T) {
+	assert := assert.New(t)
+	grpcCodeGenInfo := GRPCCodeGenInfo{
+		Package:         "pb",
+		GRPCServiceName: "TestService",
+		GRPCMethods: []GRPCMethod{
+			{
+				Name:         "Hello",
+				RequestType:  "HReq",
+				ResponseType: "HRes",
+				Comment:      " Hello greets.\n\n It never fails.\n",
+			},
+		},
+	}
+	code, err := GenerateGRPCTestCode(grpcCodeGenInfo)
+	assert.NoError(err)
+	assert.Contains(code, "\tClose() error\n\t// Hello greets.\n\t//\n\t// It never fails.\n\tBuildHelloRequest(")
+	assert.Contains(code, "// testHello runs the test case of Hello, which is documented in the proto file as follows.\n//\n// Hello greets.\n//\n// It never fails.\nfunc (runner *TestServiceTestRunner) testHello(")
+}
+
+func TestGenerateGRPCTestMethodCode(t *testing.T) {
+	assert := assert.New(t)
+	grpcCodeGenInfo := GRPCCodeGenInfo{
+		Package:         "pb",
+		GRPCServiceName: "TestService",
+		GRPCMethods: []GRPCMethod{
+			{
+				Name:         "Hello",
+				RequestType:  "HReq",
+				ResponseType: "HRes",
+			},
+			{
+				Name:         "Bye",
+				RequestType:  "BReq",
+				ResponseType: "BRes",
+			},
+		},
+		SplitMethods: true,
+	}
+	code, err := GenerateGRPCTestCode(grpcCodeGenInfo)
+	assert.NoError(err)
+	assert.Contains(code, "case \"Hello\":\n\t\treturn runner.methodHello(), true")
+	assert.NotContains(code, "func (runner *TestServiceTestRunner) methodHello() grpcTestServiceMethod {")
+	assert.NotContains(code, "func (runner *TestServiceTestRunner) CompareHello(")
+	assert.True(strings.HasSuffix(code, "\treturn runner.unmarshalMessage(reqJSON, req)\n}\n"))
+
+	code, err = GenerateGRPCTestMethodCode(grpcCodeGenInfo, "Hello")
+	assert.NoError(err)
+	assert.True(strings.HasPrefix(code, "\npackage pb\n\nimport (\n\t\"context\"\n"))
+	assert.Contains(code, "func (runner *TestServiceTestRunner) methodHello() grpcTestServiceMethod {")
+	assert.Contains(code, "func (runner *TestServiceTestRunner) BuildHelloRequest(request map[string]interface{}) (*HReq, error) {")
+	assert.Contains(code, "func (runner *TestServiceTestRunner) CompareHello(")
+	assert.NotContains(code, "Bye")
+
+	grpcCodeGenInfo.Dispatch = DispatchReflect
+	code, err = GenerateGRPCTestMethodCode(grpcCodeGenInfo, "Bye")
+	assert.NoError(err)
+	assert.NotContains(code, "\"google.golang.org/grpc\"")
+	assert.NotContains(code, "func (runner *TestServiceTestRunner) methodBye() grpcTestServiceMethod {")
+	assert.Contains(code, "func (runner *TestServiceTestRunner) PollUntilBye(")
+	assert.Contains(code, "func (runner *TestServiceTestRunner) CompareBye(")
+
+	_, err = GenerateGRPCTestMethodCode(grpcCodeGenInfo, "Unknown")
+	assert.Error(err)
+	grpcCodeGenInfo.SplitMethods = false
+	_, err = GenerateGRPCTestMethodCode(grpcCodeGenInfo, "Hello")
+	assert.Error(err)
+}
+
+func TestGenerateCLICode(t *testing.T) {
+	assert := assert.New(t)
+	grpcCodeGenInfo := GRPCCodeGenInfo{
+		Package:         "pb",
+		GRPCServiceName: "TestService",
+		GRPCMethods: []GRPCMethod{
+			{
+				Name:         "Hello",
+				RequestType:  "HReq",
+				ResponseType: "HRes",
+			},
+		},
+		ImportPath: "example.com/test/pb",
+	}
+	code, err := GenerateCLICode(grpcCodeGenInfo)
+	assert.NoError(err)
+	assert.Contains(code, "package main")
+	assert.Contains(code, `pb "example.com/test/pb"`)
+	assert.Contains(code, "pb.NewTestClientForTarget(*target, dialOption)")
+	assert.Contains(code, "runner.RunScenario(t, *scenario, nil)")
+
+	grpcCodeGenInfo.ImportPath = ""
+	_, err = GenerateCLICode(grpcCodeGenInfo)
+	assert.Error(err)
+}
+
+func TestGenerateGinkgoCode(t *testing.T) {
+	assert := assert.New(t)
+	grpcCodeGenInfo := GRPCCodeGenInfo{
+		Package:         "pb",
+		GRPCServiceName: "TestService",
+		GRPCMethods: []GRPCMethod{
+			{
+				Name:         "Hello",
+				RequestType:  "HReq",
+				ResponseType: "HRes",
+			},
+		},
+	}
+	code, err := GenerateGinkgoCode(grpcCodeGenInfo)
+	assert.NoError(err)
+	assert.True(strings.HasPrefix(code, "\npackage pb\n"))
+	assert.Contains(code, `"github.com/onsi/ginkgo/v2"`)
+	assert.Contains(code, "func (runner *TestServiceTestRunner) DescribeScenario(jsonPath string, compareFuncMap map[string]*func(expectedResponse, response interface{}) error) bool {")
+	assert.Contains(code, "runner.runTest(runner.baseContext(t), t, jsonPath, i, testCase, compareFuncMap, variables)")
+
+	grpcCodeGenInfo.GRPCMethods = nil
+	_, err = GenerateGinkgoCode(grpcCodeGenInfo)
+	assert.Error(err)
+}
+
+// TestGeneratedCodeCompilesWithCurrentGRPC compiles the generated code against the stubs generated by
+// the current protoc-gen-go and protoc-gen-go-grpc in testdata/currentgrpc, whose go.mod pins a current grpc-go.
+// The stubs are generated from examples/sample.proto. It is skipped in the short mode or if the modules can not be downloaded.
+func TestGeneratedCodeCompilesWithCurrentGRPC(t *testing.T) {
+	if testing.Short() {
+		t.Skip("it downloads the modules")
+	}
+	goCmd, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("the go command is not found")
+	}
+	for _, c := range []struct {
+		dispatch     string
+		splitMethods bool
+	}{
+		{DispatchSwitch, false},
+		{DispatchReflect, false},
+		{DispatchSwitch, true},
+		{DispatchReflect, true},
+	} {
+		name := c.dispatch
+		if c.splitMethods {
+			name += "_split"
+		}
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+			dir, err := ioutil.TempDir("", "stest")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+			for _, file := range []string{"go.mod", "go.sum", "pb/sample.pb.go", "pb/sample_grpc.pb.go"} {
+				data, err := ioutil.ReadFile(filepath.Join("testdata", "currentgrpc", file))
+				if err != nil {
+					t.Fatal(err)
+				}
+				writeFile(t, filepath.Join(dir, file), data)
+			}
+			grpcCodeGenInfo := GRPCCodeGenInfo{
+				Package:         "pb",
+				GRPCServiceName: "Sample",
+				GRPCMethods: []GRPCMethod{
+					{Name: "Hello", RequestType: "HelloRequest", ResponseType: "HelloResponse"},
+					{Name: "Bye", RequestType: "ByeRequest", ResponseType: "ByeResponse"},
+					{Name: "GetUser", RequestType: "GetUserRequest", ResponseType: "User"},
+				},
+				Dispatch:     c.dispatch,
+				ImportPath:   "stest.test/compat/pb",
+				SplitMethods: c.splitMethods,
+			}
+			code, err := GenerateGRPCTestCode(grpcCodeGenInfo)
+			assert.NoError(err)
+			assert.NotContains(code, "grpc.Code(")
+			assert.NotContains(code, "grpc.Errorf(")
+			writeFile(t, filepath.Join(dir, "pb", "sample_scenariotest.go"), []byte(code))
+			if c.splitMethods {
+				for _, method := range grpcCodeGenInfo.GRPCMethods {
+					methodCode, err := GenerateGRPCTestMethodCode(grpcCodeGenInfo, method.Name)
+					assert.NoError(err)
+					writeFile(t, filepath.Join(dir, "pb", "sample_"+strings.ToLower(method.Name)+"_scenariotest.go"), []byte(methodCode))
+				}
+			}
+			cliCode, err := GenerateCLICode(grpcCodeGenInfo)
+			assert.NoError(err)
+			writeFile(t, filepath.Join(dir, "sample_stest", "main.go"), []byte(cliCode))
+			ginkgoCode, err := GenerateGinkgoCode(grpcCodeGenInfo)
+			assert.NoError(err)
+			writeFile(t, filepath.Join(dir, "pb", "sample_ginkgo_scenariotest.go"), []byte(ginkgoCode))
+
+			download := exec.Command(goCmd, "mod", "download")
+			download.Dir = dir
+			if out, err := download.CombinedOutput(); err != nil {
+				t.Skipf("the modules can not be downloaded: %v\n%s", err, out)
+			}
+			vet := exec.Command(goCmd, "vet", "./...")
+			vet.Dir = dir
+			out, err := vet.CombinedOutput()
+			assert.NoError(err, string(out))
+		})
+	}
+}
+
+func writeFile(t *testing.T, path string, data []byte) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+var expectedCode = `
+package pb
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	mathrand "math/rand"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"runtime/debug"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/backoff"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/stats"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/testing/protocmp"
+)
+
+// TestServiceTestRunner is a runner to run the TestService service test.
+type TestServiceTestRunner struct {
+	Client TestServiceClient
+	// AllowUnknownFields makes the runner ignore the unknown keys in the scenario instead of failing,
+	// which would otherwise catch misspelled keys.
+	AllowUnknownFields bool
+	// WarnUncoveredActions makes AssertFullCoverage log the gRPC methods without test cases instead of failing.
+	WarnUncoveredActions bool
+	// BaseContext is the root context of the calls, such as a context carrying the metadata or the deadline of a surrounding test.
+	// The timeout_ms and the metadata of the test cases are layered on top of it.
+	BaseContext context.Context
+	// Decoder decodes the scenario files into the test cases instead of JSON, so that the scenario can be written in another format.
+	// The test cases have the same keys as in JSON.
+	Decoder func(data []byte) ([]map[string]interface{}, error)
+	// MaxLoadErrorRate is the rate of the unexpected errors from 0 to 1 above which RunGRPCLoad fails.
+	MaxLoadErrorRate float64
+	// AfterAll is called once after all the test cases of RunScenario have run, so that it can assert on the whole scenario
+	// such as the number of the created entities. captures has the captured variables, and the responses of the sequential
+	// test cases in the order of execution as []proto.Message under "$responses". The other internal variables of the runner,
+	// whose names start with $, are left out. Nil means nothing is called.
+	AfterAll func(t TestServiceReporter, captures map[string]interface{})
+	// SnapshotPath is the path of the snapshot file of the outcomes of the calls of the sequential test cases of RunScenario.
+	// If it is not empty, the scenario also fails if the outcomes differ from the snapshot. See UpdateSnapshotEnvKey to write it.
+	SnapshotPath string
+	// FieldComparers compares the messages of the full names such as google.protobuf.Timestamp wherever they appear in the responses,
+	// instead of proto.Equal, when the responses are compared without the compareFunc. A comparer returns an error if they are not equal.
+	FieldComparers map[string]func(expected, actual proto.Message) error
+	// SkipUnless is consulted with the action of each test case before it runs, and the test case is skipped if it returns false,
+	// so that a scenario can run against the servers not supporting some of the methods. Nil means all the test cases run.
+	SkipUnless func(action string) bool
+	// ValidateScenario makes RunScenario check the loaded scenario as TestServiceLintScenario does before calling any method,
+	// and fail with all of the problems found. It is off by default to save the cost of the checks on every run.
+	ValidateScenario bool
+	// RedactVariable returns the value of the captured variable named name to be logged by RunScenario instead of the value,
+	// such as "***" for the secrets. Nil means the values are logged as they are.
+	RedactVariable func(name string, value interface{}) interface{}
+	// LogConnectionState makes a failed test case log ConnectionState, which helps to diagnose the failures caused by the connections.
+	// It logs the connectivity states only, and the runner does not provide channelz.
+	LogConnectionState bool
+	// Clock measures the sleeps, the delays and the timeouts of the test cases, WaitHealthy and RunGRPCLoad,
+	// so that the time-dependent behavior of the runner can be tested with a fake clock. Nil means the real time.
+	Clock TestServiceClock
+	// TypeResolver resolves the types of google.protobuf.Any in the requests, the expected responses and the error details of the scenario.
+	// Nil means protoregistry.GlobalTypes, which has the types linked into the test.
+	TypeResolver TestServiceTypeResolver
+	// CompareClients are the other implementations of the client, such as a shim, which are called with the same request as Client
+	// in every test case. The test case fails if the response or the error of one of them differs from that of Client.
+	CompareClients []TestServiceClient
+	// PropagatePanics lets a panic in a test case, such as one in a compare function, crash the test binary as it does without the runner.
+	// False fails only the test case with the stack of the panic, so that the rest of the test cases still run.
+	PropagatePanics bool
+	conn            *grpc.ClientConn
+	callStatsMu     sync.Mutex
+	callStats       map[string]map[codes.Code]int
+	// patterns caches the regular expressions compiled by compilePattern.
+	patternsMu sync.Mutex
+	patterns   map[string]*regexp.Regexp
+	// target and dialOptions are those of conn, which are used to dial the connections of the authority of the test cases.
+	target           string
+	dialOptions      []grpc.DialOption
+	authorityConnsMu sync.Mutex
+	authorityConns   map[string]*grpc.ClientConn
+}
+
+// TestServiceTester is the interface of TestServiceTestRunner,
+// which can be replaced by a fake in the unit tests of the code using the runner.
+type TestServiceTester interface {
+	RunGRPCTest(t *testing.T, jsonPath string, compareFuncMap map[string]*func(expectedResponse, response interface{}) error)
+	RunScenario(t TestServiceReporter, jsonPath string, compareFuncMap map[string]*func(expectedResponse, response interface{}) error)
+	RunGRPCTestWithOptions(t *testing.T, jsonPath string, options TestServiceRunOptions)
+	RunGRPCTestContext(ctx context.Context, t *testing.T, jsonPath string, compareFuncMap map[string]*func(expectedResponse, response interface{}) error)
+	RunScenarioWithOptions(t TestServiceReporter, jsonPath string, options TestServiceRunOptions)
+	RunCase(ctx context.Context, t *testing.T, testCase map[string]interface{}, compareFuncMap map[string]*func(expectedResponse, response interface{}) error)
+	CaptureScenario(t TestServiceReporter, jsonPath string) []TestServiceCapturedCase
+	DiffCaptures(before, after []TestServiceCapturedCase) string
+	RunGRPCLoad(t *testing.T, jsonPath string, concurrency int, duration time.Duration) TestServiceLoadResult
+	AssertFullCoverage(t *testing.T, jsonPath string)
+	CallStats() map[string]map[codes.Code]int
+	ActionCounts() map[string]int
+	ConnectionState() string
+	WaitHealthy(ctx context.Context, timeout time.Duration) error
+	Close() error
+	BuildHelloRequest(request map[string]interface{}) (*HReq, error)
+	PollUntilHello(ctx context.Context, t *testing.T, req *HReq, predicate func(res *HRes, err error) bool, timeout, interval time.Duration) *HRes
+	CompareHello(expectedResponse, response *HRes, compareFunc *func(expectedResponse, response interface{}) error) error
+	BuildByeRequest(request map[string]interface{}) (*BReq, error)
+	PollUntilBye(ctx context.Context, t *testing.T, req *BReq, predicate func(res *BRes, err error) bool, timeout, interval time.Duration) *BRes
+	CompareBye(expectedResponse, response *BRes, compareFunc *func(expectedResponse, response interface{}) error) error
+}
+
+var _ TestServiceTester = (*TestServiceTestRunner)(nil)
+
+// TestServiceReporter reports the results of the test cases run by RunScenario.
+// *testing.T is adapted by NewTestServiceTestingReporter, and another implementation can run the scenario outside of go test.
+type TestServiceReporter interface {
+	// Run runs f as a subtest named name and reports whether it succeeded.
+	Run(name string, f func(t TestServiceReporter)) bool
+	Fatalf(format string, args ...interface{})
+	Errorf(format string, args ...interface{})
+	Skip(args ...interface{})
+	Logf(format string, args ...interface{})
+	// Parallel signals that the subtest may run in parallel with the other parallel subtests.
+	Parallel()
+	Failed() bool
+}
+
+// NewTestServiceTestingReporter returns the TestServiceReporter reporting to t.
+func NewTestServiceTestingReporter(t *testing.T) TestServiceReporter {
+	return testingTestServiceReporter{t: t}
+}
+
+type testingTestServiceReporter struct {
+	t *testing.T
+}
+
+func (r testingTestServiceReporter) Run(name string, f func(t TestServiceReporter)) bool {
+	return r.t.Run(name, func(t *testing.T) {
+		f(testingTestServiceReporter{t: t})
+	})
+}
+
+func (r testingTestServiceReporter) Fatalf(format string, args ...interface{}) {
+	r.t.Helper()
+	r.t.Fatalf(format, args...)
+}
+
+func (r testingTestServiceReporter) Errorf(format string, args ...interface{}) {
+	r.t.Helper()
+	r.t.Errorf(format, args...)
+}
+
+func (r testingTestServiceReporter) Skip(args ...interface{}) {
+	r.t.Helper()
+	r.t.Skip(args...)
+}
+
+func (r testingTestServiceReporter) Logf(format string, args ...interface{}) {
+	r.t.Helper()
+	r.t.Logf(format, args...)
+}
+
+func (r testingTestServiceReporter) Parallel() {
+	r.t.Parallel()
+}
+
+func (r testingTestServiceReporter) Failed() bool {
+	return r.t.Failed()
+}
+
+// Context returns t.Context() when built with Go 1.24 or later, which is canceled when the test ends,
+// so that in-flight calls do not outlive a failed test. Otherwise it is context.Background().
+func (r testingTestServiceReporter) Context() context.Context {
+	var tb interface{} = r.t
+	if tc, ok := tb.(interface{ Context() context.Context }); ok {
+		return tc.Context()
+	}
+	return context.Background()
+}
+
+// NewTestClient returns new TestServiceRunner.
+func NewTestClient(client TestServiceClient) *TestServiceTestRunner {
+	return &TestServiceTestRunner{
+		Client: client,
+	}
+}
+
+// NewTestClientForTarget dials the target and returns new TestServiceRunner using the connection.
+// The connection is closed by Close of the runner.
+// To connect through a unix socket or another net.Conn, pass grpc.WithContextDialer in opts.
+func NewTestClientForTarget(target string, opts ...grpc.DialOption) (*TestServiceTestRunner, error) {
+	opts = withTestServiceEncodingStats(opts)
+	conn, err := grpc.Dial(target, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &TestServiceTestRunner{
+		Client:      NewTestServiceClient(conn),
+		conn:        conn,
+		target:      target,
+		dialOptions: opts,
+	}, nil
+}
+
+// encodingMetadataKey is the header of the compression of the response, which gRPC hides from the header of the call.
+const encodingMetadataKey = "grpc-encoding"
+
+// encodingTestServiceKey is the key of the context of a call which has the pointer to the compression of the response set by encodingTestServiceStatsHandler.
+type encodingTestServiceKey struct{}
+
+// encodingTestServiceStatsHandler is the stats.Handler of the connections of the runner capturing the compression of the responses,
+// so that the grpc-encoding can be asserted as a header.
+type encodingTestServiceStatsHandler struct{}
+
+func (encodingTestServiceStatsHandler) TagRPC(ctx context.Context, _ *stats.RPCTagInfo) context.Context {
+	return ctx
+}
+
+func (encodingTestServiceStatsHandler) HandleRPC(ctx context.Context, s stats.RPCStats) {
+	if in, ok := s.(*stats.InHeader); ok && in.Client {
+		if encoding, ok := ctx.Value(encodingTestServiceKey{}).(*string); ok {
+			*encoding = in.Compression
+		}
+	}
+}
+
+func (encodingTestServiceStatsHandler) TagConn(ctx context.Context, _ *stats.ConnTagInfo) context.Context {
+	return ctx
+}
+
+func (encodingTestServiceStatsHandler) HandleConn(context.Context, stats.ConnStats) {}
+
+// withTestServiceEncodingStats returns the dial options with encodingTestServiceStatsHandler, which is replaced by the stats handler in opts if any.
+func withTestServiceEncodingStats(opts []grpc.DialOption) []grpc.DialOption {
+	return append([]grpc.DialOption{grpc.WithStatsHandler(encodingTestServiceStatsHandler{})}, opts...)
+}
+
+// TestServiceDialConfig configures how NewTestClientForTargetBlocking waits for a server that is still starting.
+// A zero Timeout is the default of the runner, and the other zero values are the defaults of gRPC.
+type TestServiceDialConfig struct {
+	// Timeout bounds how long the dial blocks until the connection is ready, which gRPC does not bound. Default 10 seconds.
+	Timeout time.Duration
+	// MinBackoff is the wait before the first retry of the connection, which grows up to MaxBackoff.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// MinConnectTimeout is the minimum time to give a connection attempt to complete.
+	MinConnectTimeout time.Duration
+}
+
+const (
+	defaultDialTimeout       = 10 * time.Second
+	defaultMinConnectTimeout = 20 * time.Second
+)
+
+// connectParams returns the backoff and connect parameters of the config, falling back to the defaults of gRPC.
+func (c TestServiceDialConfig) connectParams() grpc.ConnectParams {
+	params := grpc.ConnectParams{Backoff: backoff.DefaultConfig, MinConnectTimeout: c.MinConnectTimeout}
+	if c.MinBackoff > 0 {
+		params.Backoff.BaseDelay = c.MinBackoff
+	}
+	if c.MaxBackoff > 0 {
+		params.Backoff.MaxDelay = c.MaxBackoff
+	}
+	if params.MinConnectTimeout <= 0 {
+		params.MinConnectTimeout = defaultMinConnectTimeout
+	}
+	return params
+}
+
+// NewTestClientForTargetBlocking dials the target in the same way as NewTestClientForTarget,
+// but blocks until the connection is ready so that the first calls do not fail while the server is starting,
+// for example when the server is started in the same process as the test.
+// It returns an error if the connection is not ready within the timeout of config or when ctx is done.
+func NewTestClientForTargetBlocking(ctx context.Context, target string, config TestServiceDialConfig, opts ...grpc.DialOption) (*TestServiceTestRunner, error) {
+	opts = append(withTestServiceEncodingStats(opts), grpc.WithConnectParams(config.connectParams()))
+	timeout := config.Timeout
+	if timeout <= 0 {
+		timeout = defaultDialTimeout
+	}
+	dialCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	conn, err := grpc.DialContext(dialCtx, target, append(opts, grpc.WithBlock())...)
+	if err != nil {
+		return nil, fmt.Errorf("the connection to %s is not ready: %v", target, err)
+	}
+	return &TestServiceTestRunner{
+		Client:      NewTestServiceClient(conn),
+		conn:        conn,
+		target:      target,
+		dialOptions: opts,
+	}, nil
+}
+
+// TestServiceTestEnvironment defines the endpoint and the dial options such as credentials of an environment to run the test against.
+type TestServiceTestEnvironment struct {
+	Target      string
+	DialOptions []grpc.DialOption
+}
+
+// TestEnvironmentEnvKey is the name of the environment variable that selects the environment to run the test against.
+const TestEnvironmentEnvKey = "STEST_ENV"
+
+// NewTestClientForEnvironment returns new TestServiceRunner for the environment named by the STEST_ENV environment variable.
+// environments takes an environment name as a key and value has its endpoint and dial options.
+func NewTestClientForEnvironment(environments map[string]TestServiceTestEnvironment) (*TestServiceTestRunner, error) {
+	name := os.Getenv(TestEnvironmentEnvKey)
+	if name == "" {
+		return nil, errors.New(TestEnvironmentEnvKey + " is not set")
+	}
+	env, ok := environments[name]
+	if !ok {
+		return nil, fmt.Errorf("the environment %q selected by %s is not defined", name, TestEnvironmentEnvKey)
+	}
+	return NewTestClientForTarget(env.Target, env.DialOptions...)
+}
+
+// NewTestClientForWeb returns new TestServiceRunner calling the gRPC-Web endpoint,
+// such as a server wrapped by improbable-eng/grpc-web or an Envoy proxy, at the base URL.
+// Only the unary methods are supported, and the call_options are ignored. If httpClient is nil, http.DefaultClient is used.
+func NewTestClientForWeb(baseURL string, httpClient *http.Client) *TestServiceTestRunner {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &TestServiceTestRunner{
+		Client: NewTestServiceClient(&grpcWebTestServiceConn{baseURL: strings.TrimSuffix(baseURL, "/"), client: httpClient}),
+	}
+}
+
+const (
+	grpcWebContentType     = "application/grpc-web+proto"
+	grpcWebTrailerFlag     = 0x80
+	grpcWebFrameHeaderSize = 5
+)
+
+// grpcWebTestServiceConn is a grpc.ClientConnInterface calling the unary methods by the gRPC-Web protocol over HTTP/1.1.
+type grpcWebTestServiceConn struct {
+	baseURL string
+	client  *http.Client
+}
+
+// Invoke sends the request as a gRPC-Web frame and reads the response and the status from the frames of the HTTP response.
+func (c *grpcWebTestServiceConn) Invoke(ctx context.Context, method string, args, reply interface{}, opts ...grpc.CallOption) error {
+	reqMsg, err := proto.Marshal(args.(proto.Message))
+	if err != nil {
+		return status.Errorf(codes.Internal, "the request can not be marshaled: %v", err)
+	}
+	frame := make([]byte, grpcWebFrameHeaderSize+len(reqMsg))
+	binary.BigEndian.PutUint32(frame[1:grpcWebFrameHeaderSize], uint32(len(reqMsg)))
+	copy(frame[grpcWebFrameHeaderSize:], reqMsg)
+	httpReq, err := http.NewRequest(http.MethodPost, c.baseURL+method, bytes.NewReader(frame))
+	if err != nil {
+		return status.Errorf(codes.Internal, "the gRPC-Web request can not be made: %v", err)
+	}
+	httpReq = httpReq.WithContext(ctx)
+	httpReq.Header.Set("Content-Type", grpcWebContentType)
+	httpReq.Header.Set("X-Grpc-Web", "1")
+	if md, ok := metadata.FromOutgoingContext(ctx); ok {
+		for key, values := range md {
+			for _, value := range values {
+				if strings.HasSuffix(key, "-bin") {
+					value = base64.StdEncoding.EncodeToString([]byte(value))
+				}
+				httpReq.Header.Add(key, value)
+			}
+		}
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout := time.Until(deadline)
+		if timeout <= 0 {
+			return status.FromContextError(context.DeadlineExceeded).Err()
+		}
+		httpReq.Header.Set("Grpc-Timeout", fmt.Sprintf("%dm", (timeout+time.Millisecond-1)/time.Millisecond))
+	}
+
+	httpRes, err := c.client.Do(httpReq)
+	if err != nil {
+		if ctx.Err() != nil {
+			return status.FromContextError(ctx.Err()).Err()
+		}
+		return status.Errorf(codes.Unavailable, "the gRPC-Web request failed: %v", err)
+	}
+	defer httpRes.Body.Close()
+	for _, opt := range opts {
+		if o, ok := opt.(grpc.HeaderCallOption); ok {
+			*o.HeaderAddr = metadata.MD{}
+			for key, values := range httpRes.Header {
+				o.HeaderAddr.Append(key, values...)
+			}
+		}
+	}
+	body, err := ioutil.ReadAll(httpRes.Body)
+	if err != nil {
+		if ctx.Err() != nil {
+			return status.FromContextError(ctx.Err()).Err()
+		}
+		return status.Errorf(codes.Unavailable, "the gRPC-Web response can not be read: %v", err)
+	}
+
+	// A trailers-only response has the status in the headers instead of a trailer frame.
+	trailer := httpRes.Header.Clone()
+	var resMsg []byte
+	for len(body) > 0 {
+		if len(body) < grpcWebFrameHeaderSize {
+			return status.Error(codes.Internal, "the gRPC-Web response has a truncated frame")
+		}
+		length := binary.BigEndian.Uint32(body[1:grpcWebFrameHeaderSize])
+		if uint32(len(body)-grpcWebFrameHeaderSize) < length {
+			return status.Error(codes.Internal, "the gRPC-Web response has a truncated frame")
+		}
+		payload := body[grpcWebFrameHeaderSize : grpcWebFrameHeaderSize+length]
+		if body[0]&grpcWebTrailerFlag != 0 {
+			for _, line := range strings.Split(string(payload), "\r\n") {
+				if kv := strings.SplitN(line, ":", 2); len(kv) == 2 {
+					trailer.Set(strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1]))
+				}
+			}
+		} else {
+			resMsg = payload
+		}
+		body = body[grpcWebFrameHeaderSize+length:]
+	}
+	for _, opt := range opts {
+		if o, ok := opt.(grpc.TrailerCallOption); ok {
+			*o.TrailerAddr = metadata.MD{}
+			for key, values := range trailer {
+				o.TrailerAddr.Append(key, values...)
+			}
+		}
+	}
+	code := trailer.Get("Grpc-Status")
+	if code == "" {
+		return status.Errorf(codes.Unknown, "the gRPC-Web response has no grpc-status: %s", httpRes.Status)
+	}
+	n, err := strconv.Atoi(code)
+	if err != nil {
+		return status.Errorf(codes.Unknown, "the grpc-status %q of the gRPC-Web response is invalid", code)
+	}
+	if codes.Code(n) != codes.OK {
+		message, _ := url.PathUnescape(trailer.Get("Grpc-Message"))
+		return status.Error(codes.Code(n), message)
+	}
+	if resMsg == nil {
+		return status.Error(codes.Internal, "the gRPC-Web response has no message")
+	}
+	return proto.Unmarshal(resMsg, reply.(proto.Message))
+}
+
+// NewStream returns an error because the streaming methods are not supported by the runner returned by NewTestClientForWeb.
+func (c *grpcWebTestServiceConn) NewStream(ctx context.Context, desc *grpc.StreamDesc, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	return nil, status.Errorf(codes.Unimplemented, "the streaming method %s is not supported over gRPC-Web", method)
+}
+
+// Close closes the connection dialed by NewTestClientForTarget or NewTestClientForEnvironment.
+// It does nothing for the runner returned by NewTestClient.
+func (runner *TestServiceTestRunner) Close() error {
+	if runner.conn == nil {
+		return nil
+	}
+	runner.authorityConnsMu.Lock()
+	for _, conn := range runner.authorityConns {
+		conn.Close()
+	}
+	runner.authorityConns = nil
+	runner.authorityConnsMu.Unlock()
+	return runner.conn.Close()
+}
+
+// MaxCasesEnvKey is the name of the environment variable that makes RunScenario run only the first test cases of the scenario
+// up to its number, for example for a quick smoke run of a large scenario. The test cases are counted after include and matrix are expanded.
+const MaxCasesEnvKey = "STEST_MAX_CASES"
+
+// RunGRPCTest sends a gPRC request according to the scenario written in the JSON file and tests the response.
+// compareFuncMap takes a gRPC method name as a key and value has a function that compares expected response and actual response and return an error.
+func (runner *TestServiceTestRunner) RunGRPCTest(t *testing.T, jsonPath string, compareFuncMap map[string]*func(expectedResponse, response interface{}) error) {
+	runner.RunScenario(NewTestServiceTestingReporter(t), jsonPath, compareFuncMap)
+}
+
+// RunScenario runs the scenario as RunGRPCTest does, and reports the results to t instead of *testing.T.
+func (runner *TestServiceTestRunner) RunScenario(t TestServiceReporter, jsonPath string, compareFuncMap map[string]*func(expectedResponse, response interface{}) error) {
+	runner.RunScenarioWithOptions(t, jsonPath, TestServiceRunOptions{Handlers: compareFuncMap})
+}
+
+// TestServiceRunOptions configures a run of a scenario by RunGRPCTestWithOptions or RunScenarioWithOptions,
+// so that the behaviors of the run are added to it instead of the parameters of RunGRPCTest.
+type TestServiceRunOptions struct {
+	// Handlers takes a gRPC method name as a key and value has a function that compares the responses as compareFuncMap of RunGRPCTest.
+	Handlers map[string]*func(expectedResponse, response interface{}) error
+	// IgnoreFields are the dot separated paths of the fields of the responses which are not compared in any test case, such as "updated_at".
+	IgnoreFields []string
+	// MatchMode is the matcher of the expected responses written without one, which is "$exact" or "$subset". Empty means "$exact".
+	MatchMode string
+	// Parallel runs the test cases in parallel unless the parallel of the test case is false.
+	Parallel bool
+	// StopOnFailure skips the rest of the test cases after a sequential test case has failed.
+	StopOnFailure bool
+	// Replay makes the delay_before_ms of the sequential test cases the time since the previous sequential test case started instead of ended,
+	// so that the calls are made at the recorded inter-arrival times regardless of their latencies to reproduce the shape of the traffic.
+	Replay bool
+}
+
+// runOptionsVariable is the key of variables which has the TestServiceRunOptions of the run.
+const runOptionsVariable = "$options"
+
+// replayStartVariable is the key of variables which has the time the previous sequential test case started in the Replay run.
+const replayStartVariable = "$replayStart"
+
+// actionCountsVariable is the key of variables which has the *sync.Map of the number of the calls of each action made by the run as *int64,
+// by which expected_action_counts is checked without the calls made by the other runs and the other methods of the runner.
+const actionCountsVariable = "$actionCounts"
+
+// RunGRPCTestWithOptions runs the scenario as RunGRPCTest does with the options.
+func (runner *TestServiceTestRunner) RunGRPCTestWithOptions(t *testing.T, jsonPath string, options TestServiceRunOptions) {
+	runner.RunScenarioWithOptions(NewTestServiceTestingReporter(t), jsonPath, options)
+}
+
+// RunScenarioWithOptions runs the scenario as RunGRPCTestWithOptions does, and reports the results to t instead of *testing.T.
+func (runner *TestServiceTestRunner) RunScenarioWithOptions(t TestServiceReporter, jsonPath string, runOptions TestServiceRunOptions) {
+	runner.runScenario(runner.baseContext(t), t, jsonPath, runOptions)
+}
+
+// RunGRPCTestContext runs the scenario as RunGRPCTest does with ctx as the root context of the calls instead of BaseContext.
+// When ctx is done, the rest of the test cases are not run and the test fails, so that the whole run can be aborted early.
+func (runner *TestServiceTestRunner) RunGRPCTestContext(ctx context.Context, t *testing.T, jsonPath string, compareFuncMap map[string]*func(expectedResponse, response interface{}) error) {
+	runner.runScenario(ctx, NewTestServiceTestingReporter(t), jsonPath, TestServiceRunOptions{Handlers: compareFuncMap})
+}
+
+// runScenario runs the scenario with ctx as the root context of the calls.
+func (runner *TestServiceTestRunner) runScenario(ctx context.Context, t TestServiceReporter, jsonPath string, runOptions TestServiceRunOptions) {
+	scenario, options, err := runner.loadScenario(jsonPath)
+	if err != nil {
+		panic(err)
+	}
+	switch runOptions.MatchMode {
+	case "", expectationExact, expectationSubset:
+	default:
+		t.Fatalf("the MatchMode %s is neither %s nor %s", runOptions.MatchMode, expectationExact, expectationSubset)
+	}
+	compareFuncMap := runOptions.Handlers
+	if runner.ValidateScenario {
+		if errs := runner.lintScenario(scenario, options); len(errs) > 0 {
+			problems := make([]string, len(errs))
+			for i, err := range errs {
+				problems[i] = err.Error()
+			}
+			t.Fatalf("the scenario %s is invalid:\n%s", jsonPath, strings.Join(problems, "\n"))
+		}
+	}
+	if v := os.Getenv(MaxCasesEnvKey); v != "" {
+		maxCases, err := strconv.Atoi(v)
+		if err != nil || maxCases < 0 {
+			t.Fatalf("%s must be a non-negative integer, but it is %q", MaxCasesEnvKey, v)
+		}
+		if maxCases < len(scenario) {
+			scenario = scenario[:maxCases]
+		}
+	}
+	if v, ok := options[requireHealthyJSONKey]; ok && v.(bool) {
+		if err := runner.WaitHealthy(ctx, healthCheckTimeout); err != nil {
+			t.Fatalf("%v", err)
+		}
+	}
+	interCaseDelay := 0
+	if v, ok := options[interCaseDelayMsJSONKey]; ok {
+		interCaseDelay = int(v.(float64))
+	}
+	expectedActionCounts, checksActionCounts := options[expectedActionCountsJSONKey]
+	variables := map[string]interface{}{runOptionsVariable: runOptions, actionCountsVariable: &sync.Map{}}
+	seed, err := runner.newRandom(variables)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	defer func() {
+		runner.logCaptures(t, variables)
+		if t.Failed() {
+			t.Logf("%s: %d", SeedEnvKey, seed)
+		}
+	}()
+	// parallelCases has the indexes of the parallel test cases in the scenario.
+	var parallelCases []int
+	sequentialCases := 0
+	stopped := false
+	for i, testCase := range scenario {
+		testCase = runner.optionCase(testCase, runOptions)
+		if v, ok := testCase[parallelJSONKey]; ok && v.(bool) {
+			parallelCases = append(parallelCases, i)
+			continue
+		}
+		if runOptions.StopOnFailure && t.Failed() {
+			t.Logf("the rest of the test cases are skipped by StopOnFailure")
+			stopped = true
+			break
+		}
+		if ctx.Err() != nil {
+			t.Errorf("the scenario %s was aborted before the test case %d: %v", jsonPath, i, ctx.Err())
+			stopped = true
+			break
+		}
+		if sequentialCases > 0 {
+			runner.sleep(time.Duration(interCaseDelay) * time.Millisecond)
+		}
+		sequentialCases++
+		runner.runTest(ctx, t, jsonPath, i, testCase, compareFuncMap, variables)
+	}
+	if len(parallelCases) > 0 && !stopped {
+		if ctx.Err() != nil {
+			t.Errorf("the scenario %s was aborted before the parallel test cases: %v", jsonPath, ctx.Err())
+		} else {
+			// The parallel test cases are grouped so that RunGRPCTest returns after they have finished.
+			t.Run(parallelJSONKey, func(t TestServiceReporter) {
+				for _, i := range parallelCases {
+					runner.runTest(ctx, t, jsonPath, i, runner.optionCase(scenario[i], runOptions), compareFuncMap, variables)
+				}
+			})
+		}
+	}
+	if checksActionCounts {
+		if err := runner.checkActionCounts(expectedActionCounts, variables); err != nil {
+			t.Errorf("%v", err)
+		}
+	}
+	if runner.AfterAll != nil {
+		captures := map[string]interface{}{}
+		for name, value := range variables {
+			if !strings.HasPrefix(name, "$") || name == responsesVariable {
+				captures[name] = value
+			}
+		}
+		runner.AfterAll(t, captures)
+	}
+	if runner.SnapshotPath != "" {
+		if err := runner.checkSnapshot(variables); err != nil {
+			t.Errorf("%v", err)
+		}
+	}
+	if recordPath := os.Getenv(RecordEnvKey); recordPath != "" {
+		if err := runner.writeScenario(recordPath, scenario); err != nil {
+			t.Fatalf("%v", err)
+		}
+	}
+}
+
+// optionCase returns the test case run with the options, which is a copy being parallel if the options are Parallel
+// and the test case does not have the parallel. The scenario is not changed, so that it is recorded as written.
+func (runner *TestServiceTestRunner) optionCase(testCase map[string]interface{}, runOptions TestServiceRunOptions) map[string]interface{} {
+	if _, ok := testCase[parallelJSONKey]; ok || !runOptions.Parallel {
+		return testCase
+	}
+	parallelCase := make(map[string]interface{}, len(testCase)+1)
+	for key, value := range testCase {
+		parallelCase[key] = value
+	}
+	parallelCase[parallelJSONKey] = true
+	return parallelCase
+}
+
+// logCaptures logs the captured variables in the order of their names, so that the interpolation of the scenario can be debugged.
+// go test shows them if the test fails or with -v. The internal variables such as $responses are not logged.
+func (runner *TestServiceTestRunner) logCaptures(t TestServiceReporter, variables map[string]interface{}) {
+	for _, name := range runner.sortedKeys(variables) {
+		if strings.HasPrefix(name, "$") {
+			continue
+		}
+		value := variables[name]
+		if runner.RedactVariable != nil {
+			value = runner.RedactVariable(name, value)
+		}
+		t.Logf("captured %s: %s", name, runner.formatVariable(value))
+	}
+}
+
+// TestServiceLoadResult is the result of RunGRPCLoad.
+type TestServiceLoadResult struct {
+	// Calls is the number of the calls made.
+	Calls int
+	// Errors is the number of the calls whose errors were not as expected by the test cases.
+	Errors int
+	// Duration is how long the calls were made.
+	Duration time.Duration
+}
+
+// Throughput returns the number of the calls per second.
+func (r TestServiceLoadResult) Throughput() float64 {
+	if r.Duration <= 0 {
+		return 0
+	}
+	return float64(r.Calls) / r.Duration.Seconds()
+}
+
+// ErrorRate returns the rate of the unexpected errors from 0 to 1.
+func (r TestServiceLoadResult) ErrorRate() float64 {
+	if r.Calls == 0 {
+		return 0
+	}
+	return float64(r.Errors) / float64(r.Calls)
+}
+
+// loadTestServiceCall is a request of the scenario replayed by RunGRPCLoad.
+type loadTestServiceCall struct {
+	testCase map[string]interface{}
+	method   grpcTestServiceMethod
+	req      proto.Message
+	md       metadata.MD
+	opts     []grpc.CallOption
+}
+
+// RunGRPCLoad replays the requests of the scenario written in the JSON file in turn from the concurrency goroutines for the duration,
+// and logs the throughput and the error rate. The responses are not compared, and the test fails only if the rate of the errors
+// not expected by the test cases exceeds MaxLoadErrorRate. The captured variables are not available to the requests.
+func (runner *TestServiceTestRunner) RunGRPCLoad(t *testing.T, jsonPath string, concurrency int, duration time.Duration) TestServiceLoadResult {
+	t.Helper()
+	scenario, _, err := runner.loadScenario(jsonPath)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	ctx := runner.baseContext(NewTestServiceTestingReporter(t))
+	variables := map[string]interface{}{}
+	if _, err := runner.newRandom(variables); err != nil {
+		t.Fatal(err.Error())
+	}
+	var calls []loadTestServiceCall
+	for _, testCase := range scenario {
+		action, _ := testCase[actionJSONKey].(string)
+		method, ok := runner.lookupMethod(action)
+		if !ok {
+			t.Fatalf("%s is not a method of the TestService service", action)
+		}
+		method, err := runner.withAuthority(method, testCase)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		req := method.newRequest()
+		if err := runner.buildRequest(testCase[requestJSONKey], req); err != nil {
+			t.Fatalf("the request of the %s can not be built: %v", method.name, err)
+		}
+		callCtx, err := runner.outgoingContext(ctx, testCase, variables)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		md, _ := metadata.FromOutgoingContext(callCtx)
+		opts, err := runner.callOptions(testCase)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		calls = append(calls, loadTestServiceCall{testCase: testCase, method: method, req: req, md: md, opts: opts})
+	}
+	if len(calls) == 0 {
+		t.Fatalf("the scenario %s has no test case", jsonPath)
+	}
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	ctx, cancel := runner.withTimeout(ctx, duration)
+	defer cancel()
+	var mu sync.Mutex
+	var result TestServiceLoadResult
+	var wg sync.WaitGroup
+	start := runner.now()
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := w; ctx.Err() == nil; i++ {
+				call := calls[i%len(calls)]
+				_, callErr := call.method.invoke(metadata.NewOutgoingContext(ctx, call.md), call.req, call.opts...)
+				if ctx.Err() != nil {
+					// The call interrupted at the end of the duration is not counted.
+					return
+				}
+				runner.countCall(call.method.name, callErr)
+				unexpected := callErr != nil
+				if runner.expectsError(call.testCase) {
+					unexpected = runner.checkError(call.method.name, call.testCase, callErr) != nil
+				}
+				mu.Lock()
+				result.Calls++
+				if unexpected {
+					result.Errors++
+				}
+				mu.Unlock()
+			}
+		}(w)
+	}
+	wg.Wait()
+	result.Duration = runner.now().Sub(start)
+
+	t.Logf("%d calls in %v: %.1f calls/s, error rate %.2f%%", result.Calls, result.Duration, result.Throughput(), result.ErrorRate()*100)
+	if result.ErrorRate() > runner.MaxLoadErrorRate {
+		t.Errorf("the error rate %.2f%% exceeds %.2f%%", result.ErrorRate()*100, runner.MaxLoadErrorRate*100)
+	}
+	return result
+}
+
+// RecordEnvKey is the name of the environment variable that makes RunGRPCTest record the actual responses
+// as the expected responses instead of testing them, and write the scenario to the path of its value.
+const RecordEnvKey = "STEST_RECORD"
+
+// recordOutcome replaces the expectation of the test case with the actual response or error of the call.
+func (runner *TestServiceTestRunner) recordOutcome(testCase map[string]interface{}, response proto.Message, callErr error) {
+	delete(testCase, errorJSONKey)
+	if callErr != nil {
+		delete(testCase, expectedResponseJSONKey)
+		delete(testCase, responseFormatJSONKey)
+		testCase[errorExpectationJSONKey] = true
+		testCase[expectedErrorCodeJSONKey] = float64(status.Code(callErr))
+		return
+	}
+	delete(testCase, errorExpectationJSONKey)
+	delete(testCase, expectedErrorCodeJSONKey)
+	delete(testCase, forbiddenErrorCodeJSONKey)
+	delete(testCase, responseFormatJSONKey)
+	resJSON, _ := runner.marshalMessage(response)
+	var res interface{}
+	json.Unmarshal(resJSON, &res)
+	testCase[expectedResponseJSONKey] = res
+}
+
+// UpdateSnapshotEnvKey is the name of the environment variable that makes RunScenario write the outcomes of the calls to SnapshotPath
+// instead of comparing them with the snapshot when its value is 1.
+const UpdateSnapshotEnvKey = "STEST_UPDATE_SNAPSHOT"
+
+// snapshotVariable is the key of variables which has the outcomes of the calls of the sequential test cases in the order of execution.
+const snapshotVariable = "$snapshot"
+
+const (
+	snapshotResponseKey = "response"
+	snapshotMessageKey  = "message"
+)
+
+// snapshotOutcome adds the response or the error of the call to the snapshot, unless the test case runs in parallel.
+func (runner *TestServiceTestRunner) snapshotOutcome(testCase map[string]interface{}, action string, response proto.Message, callErr error, variables map[string]interface{}) {
+	if v, ok := testCase[parallelJSONKey]; runner.SnapshotPath == "" || ok && v.(bool) {
+		return
+	}
+	outcome := map[string]interface{}{actionJSONKey: action}
+	if callErr != nil {
+		st := status.Convert(callErr)
+		outcome[errorJSONKey] = map[string]interface{}{errorCodeJSONKey: st.Code().String(), snapshotMessageKey: st.Message()}
+	} else {
+		outcome[snapshotResponseKey] = response
+	}
+	outcomes, _ := variables[snapshotVariable].([]interface{})
+	variables[snapshotVariable] = append(outcomes, outcome)
+}
+
+// checkSnapshot returns an error if the outcomes of the calls differ from the snapshot in SnapshotPath,
+// or writes them to it if UpdateSnapshotEnvKey is 1.
+func (runner *TestServiceTestRunner) checkSnapshot(variables map[string]interface{}) error {
+	outcomes, _ := variables[snapshotVariable].([]interface{})
+	if outcomes == nil {
+		outcomes = []interface{}{}
+	}
+	actual, err := json.MarshalIndent(outcomes, "", "    ")
+	if err != nil {
+		return err
+	}
+	if os.Getenv(UpdateSnapshotEnvKey) == "1" {
+		return ioutil.WriteFile(runner.SnapshotPath, append(actual, '\n'), 0644)
+	}
+	expected, err := ioutil.ReadFile(runner.SnapshotPath)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("the snapshot %s does not exist. Run with %s=1 to write it", runner.SnapshotPath, UpdateSnapshotEnvKey)
+	}
+	if err != nil {
+		return err
+	}
+	expectedLines := strings.Split(strings.TrimSpace(strings.Replace(string(expected), "\r\n", "\n", -1)), "\n")
+	actualLines := strings.Split(string(actual), "\n")
+	for i := 0; i < len(expectedLines) || i < len(actualLines); i++ {
+		var expectedLine, actualLine string
+		if i < len(expectedLines) {
+			expectedLine = expectedLines[i]
+		}
+		if i < len(actualLines) {
+			actualLine = actualLines[i]
+		}
+		if expectedLine != actualLine {
+			return fmt.Errorf("the outcomes of the calls differ from the snapshot %s at the line %d. Expected: %q, Actual: %q", runner.SnapshotPath, i+1, expectedLine, actualLine)
+		}
+	}
+	return nil
+}
+
+// writeScenario writes the test cases to the file as a scenario without the locations of the included test cases.
+func (runner *TestServiceTestRunner) writeScenario(jsonPath string, scenario []map[string]interface{}) error {
+	written := make([]map[string]interface{}, len(scenario))
+	for i, testCase := range scenario {
+		written[i] = make(map[string]interface{}, len(testCase))
+		for key, value := range testCase {
+			if key != locationKey {
+				written[i][key] = value
+			}
+		}
+	}
+	scenarioData, err := json.MarshalIndent(written, "", "    ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(jsonPath, append(scenarioData, '\n'), 0644)
+}
+
+// RunCase runs a test case of the scenario as a subtest of t named by the name or the action of the test case.
+// Unlike RunGRPCTest, the test case can not refer to the variables captured by the other test cases.
+// compareFuncMap is the same as that of RunGRPCTest.
+func (runner *TestServiceTestRunner) RunCase(ctx context.Context, t *testing.T, testCase map[string]interface{}, compareFuncMap map[string]*func(expectedResponse, response interface{}) error) {
+	if err := runner.checkUnknownFields([]map[string]interface{}{testCase}, nil); err != nil {
+		panic(err)
+	}
+	variables := map[string]interface{}{}
+	seed, err := runner.newRandom(variables)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	runner.runTest(ctx, NewTestServiceTestingReporter(t), "", 0, testCase, compareFuncMap, variables)
+	if t.Failed() {
+		t.Logf("%s: %d", SeedEnvKey, seed)
+	}
+}
+
+// TestServiceCapturedCase is the outcome of the call of a test case captured by CaptureScenario.
+type TestServiceCapturedCase struct {
+	// Name is the name of the test case, which is its action unless the test case has the name.
+	Name string
+	// Response is the actual response, which is nil if the call failed.
+	Response proto.Message
+	// Err is the error of the call.
+	Err error
+}
+
+// capturedCasesVariable is the key of variables which has the *[]TestServiceCapturedCase appended by CaptureScenario.
+const capturedCasesVariable = "$captured"
+
+// CaptureScenario runs each test case of the scenario once without asserting on the response, and returns the outcomes of the calls
+// in the order of the scenario, so that the outcomes of two builds of the server can be compared by DiffCaptures.
+// The test cases run sequentially even if they are parallel, and they can refer to the captured variables as in RunScenario.
+func (runner *TestServiceTestRunner) CaptureScenario(t TestServiceReporter, jsonPath string) []TestServiceCapturedCase {
+	scenario, _, err := runner.loadScenario(jsonPath)
+	if err != nil {
+		panic(err)
+	}
+	variables := map[string]interface{}{}
+	if _, err := runner.newRandom(variables); err != nil {
+		t.Fatalf("%v", err)
+	}
+	var captured []TestServiceCapturedCase
+	variables[capturedCasesVariable] = &captured
+	for i, testCase := range scenario {
+		sequentialCase := make(map[string]interface{}, len(testCase))
+		for key, value := range testCase {
+			if key != parallelJSONKey {
+				sequentialCase[key] = value
+			}
+		}
+		runner.runTest(runner.baseContext(t), t, jsonPath, i, sequentialCase, nil, variables)
+	}
+	return captured
+}
+
+// DiffCaptures returns the differences of the outcomes captured by CaptureScenario before and after a change of the server
+// in the format of cmp.Diff with protocmp, or an empty string if they are the same.
+// The outcomes are matched in order, and the errors are compared as their google.rpc.Status.
+func (runner *TestServiceTestRunner) DiffCaptures(before, after []TestServiceCapturedCase) string {
+	var diffs []string
+	for i := 0; i < len(before) || i < len(after); i++ {
+		if i >= len(after) {
+			diffs = append(diffs, fmt.Sprintf("the test case %d %s was captured only before", i, before[i].Name))
+			continue
+		}
+		if i >= len(before) {
+			diffs = append(diffs, fmt.Sprintf("the test case %d %s was captured only after", i, after[i].Name))
+			continue
+		}
+		outcome := func(captured TestServiceCapturedCase) map[string]proto.Message {
+			return map[string]proto.Message{snapshotResponseKey: captured.Response, "status": status.Convert(captured.Err).Proto()}
+		}
+		if diff := cmp.Diff(outcome(before[i]), outcome(after[i]), protocmp.Transform()); diff != "" {
+			diffs = append(diffs, fmt.Sprintf("the test case %d %s differs (-before +after):\n%s", i, before[i].Name, diff))
+		}
+	}
+	return strings.Join(diffs, "\n")
+}
+
+// TestServiceActions are the actions of the test cases, which are the names of the gRPC methods of the TestService service
+// in the order of the service definition.
+var TestServiceActions = []string{
+	"Hello",
+	"Bye",
+}
+
+// newMessages returns the empty request and response of the gRPC method named action.
+func (runner *TestServiceTestRunner) newMessages(action string) (proto.Message, proto.Message, bool) {
+	switch action {
+	case "Hello":
+		return &HReq{}, &HRes{}, true
+	case "Bye":
+		return &BReq{}, &BRes{}, true
+	}
+	return nil, nil, false
+}
+
+// AssertFullCoverage fails the test if the scenario written in the JSON file does not have a test case for every gRPC method of the service.
+func (runner *TestServiceTestRunner) AssertFullCoverage(t *testing.T, jsonPath string) {
+	scenario, _, err := runner.loadScenario(jsonPath)
+	if err != nil {
+		panic(err)
+	}
+	covered := map[string]bool{}
+	for _, testCase := range scenario {
+		if action, ok := testCase[actionJSONKey].(string); ok {
+			covered[action] = true
+		}
+	}
+	for _, name := range TestServiceActions {
+		if covered[name] {
+			continue
+		}
+		if runner.WarnUncoveredActions {
+			t.Logf("the scenario %s has no test case of %s", jsonPath, name)
+		} else {
+			t.Errorf("the scenario %s has no test case of %s", jsonPath, name)
+		}
+	}
+}
+
+// TestServiceLintScenario returns all of the problems found in the scenario written in the JSON file without calling the server,
+// such as unknown keys, unknown actions, invalid error codes, and requests or expected responses not matching the messages.
+func TestServiceLintScenario(jsonPath string) []error {
+	runner := &TestServiceTestRunner{AllowUnknownFields: true}
+	scenario, options, err := runner.loadScenario(jsonPath)
+	if err != nil {
+		return []error{err}
+	}
+	return runner.lintScenario(scenario, options)
+}
+
+// lintScenario returns the problems of the loaded scenario found by TestServiceLintScenario.
+func (runner *TestServiceTestRunner) lintScenario(scenario []map[string]interface{}, options map[string]interface{}) []error {
+	var errs []error
+	for _, key := range runner.sortedKeys(options) {
+		if !scenarioTestServiceJSONKeys[key] {
+			errs = append(errs, fmt.Errorf("%s of the scenario is unknown", key))
+		}
+	}
+	if v, ok := options[expectedActionCountsJSONKey]; ok {
+		errs = append(errs, runner.lintActionCounts(v)...)
+	}
+	for i, testCase := range scenario {
+		location := fmt.Sprintf("the test case %d", i)
+		if v, ok := testCase[locationKey].(string); ok {
+			location = v
+		}
+		for _, err := range runner.lintTestCase(testCase) {
+			errs = append(errs, fmt.Errorf("%s: %v", location, err))
+		}
+	}
+	return errs
+}
+
+// lintActionCounts returns the problems of the expected_action_counts of the scenario, which must map the actions to the numbers of their calls.
+func (runner *TestServiceTestRunner) lintActionCounts(value interface{}) []error {
+	counts, ok := value.(map[string]interface{})
+	if !ok {
+		return []error{fmt.Errorf("the %s is not an object", expectedActionCountsJSONKey)}
+	}
+	var errs []error
+	for _, action := range runner.sortedKeys(counts) {
+		if count, ok := counts[action].(float64); !ok || count < 0 || count != float64(int64(count)) {
+			errs = append(errs, fmt.Errorf("the %s of %s is not a non-negative integer", expectedActionCountsJSONKey, action))
+		}
+	}
+	return errs
+}
+
+// lintTestCase returns the problems of the test case found by TestServiceLintScenario.
+func (runner *TestServiceTestRunner) lintTestCase(testCase map[string]interface{}) []error {
+	var errs []error
+	for _, key := range runner.sortedKeys(testCase) {
+		if !testCaseTestServiceJSONKeys[key] && key != locationKey {
+			errs = append(errs, fmt.Errorf("%s is unknown", key))
+		}
+	}
+	action, ok := testCase[actionJSONKey].(string)
+	if !ok {
+		return append(errs, fmt.Errorf("%s is required", actionJSONKey))
+	}
+	req, res, ok := runner.newMessages(action)
+	if !ok {
+		return append(errs, fmt.Errorf("%s is not a method of the TestService service", action))
+	}
+	if v, ok := testCase[requestJSONKey]; ok {
+		if err := runner.buildRequest(v, req); err != nil {
+			errs = append(errs, fmt.Errorf("the %s is not a request of %s: %v", requestJSONKey, action, err))
+		}
+	}
+	if v, ok := testCase[successRuleJSONKey]; ok && v != successRuleAll && v != successRuleOnce {
+		errs = append(errs, fmt.Errorf("the %s %v is unknown", successRuleJSONKey, v))
+	}
+	if v, ok := testCase[expectedPeerRegexJSONKey]; ok {
+		if pattern, isString := v.(string); !isString {
+			errs = append(errs, fmt.Errorf("the %s is not a string", expectedPeerRegexJSONKey))
+		} else if _, err := regexp.Compile(pattern); err != nil {
+			errs = append(errs, fmt.Errorf("the %s is invalid: %v", expectedPeerRegexJSONKey, err))
+		}
+	}
+	if v, ok := testCase[pollJSONKey]; ok {
+		options, _ := v.(map[string]interface{})
+		if timeout, ok := options[timeoutMsJSONKey].(float64); !ok || timeout <= 0 {
+			errs = append(errs, fmt.Errorf("the %s requires the positive %s", pollJSONKey, timeoutMsJSONKey))
+		}
+		for _, key := range []string{loopJSONKey, successRuleJSONKey} {
+			if _, ok := testCase[key]; ok {
+				errs = append(errs, fmt.Errorf("the %s can not be combined with %s", pollJSONKey, key))
+			}
+		}
+	}
+	specs := []map[string]interface{}{testCase}
+	if v, ok := testCase[assertionsJSONKey]; ok {
+		assertions, _ := v.([]interface{})
+		for i, assertion := range assertions {
+			spec, ok := assertion.(map[string]interface{})
+			if !ok {
+				errs = append(errs, fmt.Errorf("the assertion %d is not an object", i))
+				continue
+			}
+			switch spec[assertionTypeJSONKey] {
+			case assertionTypeResponse, assertionTypeError, assertionTypeHeader, assertionTypeLatency:
+				specs = append(specs, spec)
+			case assertionTypeDeadline:
+				if _, ok := spec[fieldJSONKey].(string); !ok {
+					errs = append(errs, fmt.Errorf("the assertion %d requires %s", i, fieldJSONKey))
+				}
+				if _, ok := testCase[timeoutMsJSONKey]; !ok {
+					errs = append(errs, fmt.Errorf("the assertion %d requires %s of the test case", i, timeoutMsJSONKey))
+				}
+			case assertionTypeTrailer:
+				expected, _ := spec[expectedTrailerJSONKey].(map[string]interface{})
+				for key, value := range expected {
+					switch value := value.(type) {
+					case string:
+					case float64:
+						if value != float64(int64(value)) {
+							errs = append(errs, fmt.Errorf("the %s %s of the assertion %d is not an integer", expectedTrailerJSONKey, key, i))
+						}
+					default:
+						errs = append(errs, fmt.Errorf("the %s %s of the assertion %d is neither a string nor an integer", expectedTrailerJSONKey, key, i))
+					}
+				}
+			default:
+				errs = append(errs, fmt.Errorf("the type %v of the assertion %d is unknown", spec[assertionTypeJSONKey], i))
+			}
+		}
+	}
+	for _, spec := range specs {
+		for _, key := range []string{expectedErrorCodeJSONKey, forbiddenErrorCodeJSONKey} {
+			v, ok := spec[key]
+			if !ok {
+				continue
+			}
+			values, isArray := v.([]interface{})
+			if !isArray || key != expectedErrorCodeJSONKey {
+				values = []interface{}{v}
+			}
+			for _, value := range values {
+				if code, isNumber := value.(float64); !isNumber || code != float64(int(code)) || code < float64(codes.OK) || code > float64(codes.Unauthenticated) {
+					errs = append(errs, fmt.Errorf("the %s %v is not a gRPC error code", key, value))
+				}
+			}
+		}
+		if v, ok := spec[errorJSONKey]; ok {
+			expected, isObject := v.(map[string]interface{})
+			if !isObject {
+				errs = append(errs, fmt.Errorf("the %s is not an object", errorJSONKey))
+			} else if code, ok := expected[errorCodeJSONKey]; ok {
+				if _, err := runner.parseCode(code); err != nil {
+					errs = append(errs, err)
+				}
+			}
+		}
+		if v, ok := spec[expectedStatusJSONKey]; ok {
+			if _, err := runner.parseStatus(v); err != nil {
+				errs = append(errs, fmt.Errorf("the %s is not a google.rpc.Status: %v", expectedStatusJSONKey, err))
+			}
+		}
+		if err := runner.lintExpectedResponse(spec, res); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// lintExpectedResponse returns an error if the expected_response of spec, which is a test case or an assertion, is not a response of the message.
+func (runner *TestServiceTestRunner) lintExpectedResponse(spec map[string]interface{}, res proto.Message) error {
+	v, ok := spec[expectedResponseJSONKey]
+	if !ok {
+		return nil
+	}
+	switch spec[responseFormatJSONKey] {
+	case nil, responseFormatJSON:
+		if _, ok := runner.responseReference(spec); ok {
+			return nil
+		}
+		expectation, expected := runner.unwrapExpectation(v, expectationExact)
+		switch expectation {
+		case expectationAnyOf:
+			alternatives, ok := expected.([]interface{})
+			if !ok {
+				return fmt.Errorf("the %s of the %s is not an array", expectationAnyOf, expectedResponseJSONKey)
+			}
+			for _, alternative := range alternatives {
+				alternativeSpec := map[string]interface{}{expectedResponseJSONKey: alternative}
+				if err := runner.lintExpectedResponse(alternativeSpec, proto.Clone(res)); err != nil {
+					return err
+				}
+			}
+			return nil
+		case expectationSchema:
+			if _, ok := expected.(map[string]interface{}); !ok {
+				return fmt.Errorf("the %s of the %s is not an object", expectationSchema, expectedResponseJSONKey)
+			}
+			return nil
+		case expectationEchoRequest:
+			if _, err := runner.echoPaths(expected); err != nil {
+				return err
+			}
+			return nil
+		}
+		wrapped, err := runner.wrapRepeated(res.ProtoReflect().Descriptor(), expected)
+		if err != nil {
+			return err
+		}
+		resJSON, _ := json.Marshal(runner.extractMatchers(wrapped, "", map[string]map[string]interface{}{}))
+		if err := runner.unmarshalMessage(resJSON, res); err != nil {
+			return fmt.Errorf("the %s is not a response: %v", expectedResponseJSONKey, err)
+		}
+	case responseFormatPrototext:
+		resText, _ := v.(string)
+		if err := prototext.Unmarshal([]byte(resText), res); err != nil {
+			return fmt.Errorf("the %s is not a response in prototext: %v", expectedResponseJSONKey, err)
+		}
+	default:
+		return fmt.Errorf("the %s %v is unknown", responseFormatJSONKey, spec[responseFormatJSONKey])
+	}
+	return nil
+}
+
+// sortedKeys returns the keys of the object in order so that the problems are reported in the same order.
+func (runner *TestServiceTestRunner) sortedKeys(object map[string]interface{}) []string {
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// CallStats returns the number of the calls made by the runner for each gRPC method and status code.
+func (runner *TestServiceTestRunner) CallStats() map[string]map[codes.Code]int {
+	runner.callStatsMu.Lock()
+	defer runner.callStatsMu.Unlock()
+	stats := map[string]map[codes.Code]int{}
+	for name, counts := range runner.callStats {
+		stats[name] = map[codes.Code]int{}
+		for code, count := range counts {
+			stats[name][code] = count
+		}
+	}
+	return stats
+}
+
+// ActionCounts returns the number of the calls made by the runner for each action regardless of the status code.
+func (runner *TestServiceTestRunner) ActionCounts() map[string]int {
+	actionCounts := map[string]int{}
+	for name, counts := range runner.CallStats() {
+		for _, count := range counts {
+			actionCounts[name] += count
+		}
+	}
+	return actionCounts
+}
+
+// ConnectionState returns the targets and the connectivity states of the connections of the runner one per line, such as "localhost:50051: READY".
+// The connections dialed for the authority of the test cases follow with the authority. The runner created by NewTestClient has no connection.
+// It is a dump of the connectivity states and not of channelz, which grpc can only turn on for the whole process by importing its channelz service.
+func (runner *TestServiceTestRunner) ConnectionState() string {
+	var states []string
+	if runner.conn != nil {
+		states = append(states, fmt.Sprintf("%s: %v", runner.conn.Target(), runner.conn.GetState()))
+	}
+	runner.authorityConnsMu.Lock()
+	defer runner.authorityConnsMu.Unlock()
+	authorities := make([]string, 0, len(runner.authorityConns))
+	for authority := range runner.authorityConns {
+		authorities = append(authorities, authority)
+	}
+	sort.Strings(authorities)
+	for _, authority := range authorities {
+		conn := runner.authorityConns[authority]
+		states = append(states, fmt.Sprintf("%s (%s): %v", conn.Target(), authority, conn.GetState()))
+	}
+	return strings.Join(states, "\n")
+}
+
+// checkActionCounts returns an error listing the actions which did not run the expected number of times,
+// which is the number of the calls made by the run of variables.
+func (runner *TestServiceTestRunner) checkActionCounts(expected interface{}, variables map[string]interface{}) error {
+	expectedCounts, ok := expected.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("Scenario JSON is invalid. Because the %s is not an object.", expectedActionCountsJSONKey)
+	}
+	counts, _ := variables[actionCountsVariable].(*sync.Map)
+	var failures []string
+	for _, action := range runner.sortedKeys(expectedCounts) {
+		expectedCount, ok := expectedCounts[action].(float64)
+		if !ok {
+			return fmt.Errorf("Scenario JSON is invalid. Because the %s of %s is not a number.", expectedActionCountsJSONKey, action)
+		}
+		count := 0
+		if v, ok := counts.Load(action); ok {
+			count = int(atomic.LoadInt64(v.(*int64)))
+		}
+		if float64(count) != expectedCount {
+			failures = append(failures, fmt.Sprintf("the action %s ran %d times in the scenario. Expected: %v", action, count, expectedCount))
+		}
+	}
+	if len(failures) > 0 {
+		return errors.New(strings.Join(failures, "\n"))
+	}
+	return nil
+}
+
+// countAction adds the call of the action to the counts of the calls of the run of variables if it has them.
+func (runner *TestServiceTestRunner) countAction(name string, variables map[string]interface{}) {
+	counts, ok := variables[actionCountsVariable].(*sync.Map)
+	if !ok {
+		return
+	}
+	count, _ := counts.LoadOrStore(name, new(int64))
+	atomic.AddInt64(count.(*int64), 1)
+}
+
+// countCall adds the call of the gRPC method to the CallStats.
+func (runner *TestServiceTestRunner) countCall(name string, err error) {
+	runner.callStatsMu.Lock()
+	defer runner.callStatsMu.Unlock()
+	if runner.callStats == nil {
+		runner.callStats = map[string]map[codes.Code]int{}
+	}
+	if runner.callStats[name] == nil {
+		runner.callStats[name] = map[codes.Code]int{}
+	}
+	runner.callStats[name][status.Code(err)]++
+}
+
+// loadScenario reads the test cases of the scenario file and the options of the scenario.
+// The scenario is either an array of test cases or an object which has the test cases in cases
+// and the options such as default_metadata applied to every test case.
+// The object can include the test cases of other scenario files by include, whose paths are relative to the including file.
+// A scenario file whose name ends with .gz is decompressed by gzip.
+// The request of a test case can be read from another file by {"$file": "path"}, whose path is relative to the scenario file.
+// A test case can inherit the keys of the test case named by its template, which is applied after the includes.
+// A test case having a matrix is expanded into a test case for each row of the matrix after the templates are applied.
+func (runner *TestServiceTestRunner) loadScenario(jsonPath string) ([]map[string]interface{}, map[string]interface{}, error) {
+	scenario, options, err := runner.loadScenarioFile(jsonPath, map[string]bool{})
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := runner.applyTemplates(scenario); err != nil {
+		return nil, nil, err
+	}
+	if scenario, err = runner.expandMatrices(scenario); err != nil {
+		return nil, nil, err
+	}
+	return scenario, options, nil
+}
+
+// expandMatrices replaces each test case having a matrix, which is an array of the objects of the variables,
+// with a test case for each row of the matrix in which each ${name} is replaced with the variable of the row.
+// A string which is only a reference to a variable is replaced with the value of the variable, such as a number.
+// The references to the other variables such as the captured ones are left as they are.
+// Unless the test case has a name, each expanded test case is named by the action and the variables of the row.
+func (runner *TestServiceTestRunner) expandMatrices(scenario []map[string]interface{}) ([]map[string]interface{}, error) {
+	var expanded []map[string]interface{}
+	for i, testCase := range scenario {
+		v, ok := testCase[matrixJSONKey]
+		if !ok {
+			expanded = append(expanded, testCase)
+			continue
+		}
+		rows, ok := v.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("Scenario JSON is invalid. Because the %s of the test case %d is not an array.", matrixJSONKey, i)
+		}
+		for j, r := range rows {
+			row, ok := r.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("Scenario JSON is invalid. Because the row %d of the %s of the test case %d is not an object.", j, matrixJSONKey, i)
+			}
+			expandedCase := runner.substituteVariables(testCase, row).(map[string]interface{})
+			delete(expandedCase, matrixJSONKey)
+			if _, ok := expandedCase[nameJSONKey]; !ok {
+				var variables []string
+				for _, name := range runner.sortedKeys(row) {
+					variables = append(variables, name+"="+runner.formatVariable(row[name]))
+				}
+				expandedCase[nameJSONKey] = fmt.Sprintf("%v(%s)", testCase[actionJSONKey], strings.Join(variables, ","))
+			}
+			expanded = append(expanded, expandedCase)
+		}
+	}
+	return expanded, nil
+}
+
+// substituteVariables returns a copy of v in which each ${name} in the strings is replaced with the variable of the row.
+func (runner *TestServiceTestRunner) substituteVariables(v interface{}, row map[string]interface{}) interface{} {
+	switch value := v.(type) {
+	case string:
+		variableReference, _ := runner.compilePattern(variableReferencePattern)
+		if match := variableReference.FindStringSubmatch(value); match != nil && match[0] == value {
+			if variable, ok := row[match[1]]; ok {
+				return variable
+			}
+		}
+		return variableReference.ReplaceAllStringFunc(value, func(ref string) string {
+			if variable, ok := row[variableReference.FindStringSubmatch(ref)[1]]; ok {
+				return runner.formatVariable(variable)
+			}
+			return ref
+		})
+	case map[string]interface{}:
+		object := make(map[string]interface{}, len(value))
+		for key, element := range value {
+			object[key] = runner.substituteVariables(element, row)
+		}
+		return object
+	case []interface{}:
+		array := make([]interface{}, len(value))
+		for i, element := range value {
+			array[i] = runner.substituteVariables(element, row)
+		}
+		return array
+	}
+	return v
+}
+
+// applyTemplates replaces each test case having a template with the test case named by the template by its id,
+// merged with the keys of the test case. The objects are merged deeply, and the other values such as the arrays are replaced.
+// The template can have a template of its own, and the test case named by it is also run as a test case.
+func (runner *TestServiceTestRunner) applyTemplates(scenario []map[string]interface{}) error {
+	templates := map[string]map[string]interface{}{}
+	for i, testCase := range scenario {
+		id, ok := testCase[idJSONKey].(string)
+		if !ok {
+			continue
+		}
+		if _, ok := templates[id]; ok {
+			return fmt.Errorf("Scenario JSON is invalid. Because the id %s of the test case %d is duplicated.", id, i)
+		}
+		templates[id] = testCase
+	}
+	for i, testCase := range scenario {
+		merged, err := runner.applyTemplate(testCase, templates, map[string]bool{})
+		if err != nil {
+			return fmt.Errorf("Scenario JSON is invalid. Because %v in the test case %d.", err, i)
+		}
+		scenario[i] = merged
+	}
+	return nil
+}
+
+// applyTemplate returns the test case merged into its template. resolving has the ids of the templates being applied to detect cyclic templates.
+func (runner *TestServiceTestRunner) applyTemplate(testCase map[string]interface{}, templates map[string]map[string]interface{}, resolving map[string]bool) (map[string]interface{}, error) {
+	id, ok := testCase[templateJSONKey].(string)
+	if !ok {
+		return testCase, nil
+	}
+	if resolving[id] {
+		return nil, fmt.Errorf("the template %s is cyclic", id)
+	}
+	resolving[id] = true
+	template, ok := templates[id]
+	if !ok {
+		return nil, fmt.Errorf("the template %s is not found", id)
+	}
+	template, err := runner.applyTemplate(template, templates, resolving)
+	if err != nil {
+		return nil, err
+	}
+	var merged map[string]interface{}
+	templateJSON, _ := json.Marshal(template)
+	json.Unmarshal(templateJSON, &merged)
+	delete(merged, idJSONKey)
+	merged = runner.mergeObjects(merged, testCase)
+	delete(merged, templateJSONKey)
+	return merged, nil
+}
+
+// mergeObjects merges override into base deeply and returns base.
+func (runner *TestServiceTestRunner) mergeObjects(base, override map[string]interface{}) map[string]interface{} {
+	for key, value := range override {
+		baseObject, baseOK := base[key].(map[string]interface{})
+		overrideObject, overrideOK := value.(map[string]interface{})
+		if baseOK && overrideOK {
+			base[key] = runner.mergeObjects(baseObject, overrideObject)
+			continue
+		}
+		base[key] = value
+	}
+	return base
+}
+
+// loadScenarioFile reads the scenario file as loadScenario does.
+// loading has the absolute paths of the scenario files being loaded to detect cyclic includes.
+func (runner *TestServiceTestRunner) loadScenarioFile(jsonPath string, loading map[string]bool) ([]map[string]interface{}, map[string]interface{}, error) {
+	absPath, err := filepath.Abs(jsonPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	if loading[absPath] {
+		return nil, nil, fmt.Errorf("Scenario JSON is invalid. Because %s is included cyclically.", jsonPath)
+	}
+	loading[absPath] = true
+	defer delete(loading, absPath)
+
+	scenarioData, err := runner.readFile(jsonPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	var scenario []map[string]interface{}
+	options := map[string]interface{}{}
+	if runner.Decoder != nil {
+		if scenario, err = runner.Decoder(scenarioData); err != nil {
+			return nil, nil, fmt.Errorf("Scenario is invalid. Because %s can not be decoded: %v", jsonPath, err)
+		}
+		if err := runner.checkUnknownFields(scenario, options); err != nil {
+			return nil, nil, err
+		}
+		if err := runner.loadRequestFiles(jsonPath, scenario); err != nil {
+			return nil, nil, err
+		}
+		runner.locateCases(jsonPath, scenario, loading)
+		return scenario, options, nil
+	}
+	if !bytes.HasPrefix(bytes.TrimSpace(scenarioData), []byte("{")) {
+		if err := json.Unmarshal(scenarioData, &scenario); err != nil {
+			return nil, nil, fmt.Errorf("Scenario JSON is invalid. Because %s is not an array of test cases: %v", jsonPath, err)
+		}
+		if err := runner.checkUnknownFields(scenario, options); err != nil {
+			return nil, nil, err
+		}
+		if err := runner.loadRequestFiles(jsonPath, scenario); err != nil {
+			return nil, nil, err
+		}
+		runner.locateCases(jsonPath, scenario, loading)
+		return scenario, options, nil
+	}
+	if err := json.Unmarshal(scenarioData, &options); err != nil {
+		return nil, nil, err
+	}
+	if v, ok := options[includeJSONKey]; ok {
+		includes, ok := v.([]interface{})
+		if !ok {
+			return nil, nil, fmt.Errorf("Scenario JSON is invalid. Because the %s of %s is not an array.", includeJSONKey, jsonPath)
+		}
+		for i, include := range includes {
+			includeFile, ok := include.(string)
+			if !ok {
+				return nil, nil, fmt.Errorf("Scenario JSON is invalid. Because the element %d of the %s of %s is not a path: %v", i, includeJSONKey, jsonPath, include)
+			}
+			includePath := filepath.Join(filepath.Dir(jsonPath), includeFile)
+			included, includedOptions, err := runner.loadScenarioFile(includePath, loading)
+			if err != nil {
+				return nil, nil, err
+			}
+			scenario = append(scenario, included...)
+			runner.mergeOptions(options, includedOptions)
+		}
+		delete(options, includeJSONKey)
+	} else if _, ok := options[casesJSONKey]; !ok {
+		return nil, nil, fmt.Errorf("Scenario JSON is invalid. Because %s is required.", casesJSONKey)
+	}
+	if v, ok := options[casesJSONKey]; ok {
+		var cases []map[string]interface{}
+		casesJSON, _ := json.Marshal(v)
+		if err := json.Unmarshal(casesJSON, &cases); err != nil {
+			return nil, nil, fmt.Errorf("Scenario JSON is invalid. Because %s is not an array of test cases.", casesJSONKey)
+		}
+		if err := runner.loadRequestFiles(jsonPath, cases); err != nil {
+			return nil, nil, err
+		}
+		runner.locateCases(jsonPath, cases, loading)
+		scenario = append(scenario, cases...)
+		delete(options, casesJSONKey)
+	}
+	if err := runner.checkUnknownFields(scenario, options); err != nil {
+		return nil, nil, err
+	}
+	if v, ok := options[defaultMetadataJSONKey]; ok {
+		defaultMetadata, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, nil, fmt.Errorf("Scenario JSON is invalid. Because the %s of %s is not an object.", defaultMetadataJSONKey, jsonPath)
+		}
+		for _, key := range runner.sortedKeys(defaultMetadata) {
+			if _, ok := defaultMetadata[key].(string); !ok {
+				return nil, nil, fmt.Errorf("Scenario JSON is invalid. Because the %s %s of %s is not a string.", defaultMetadataJSONKey, key, jsonPath)
+			}
+		}
+		for _, testCase := range scenario {
+			md := map[string]interface{}{}
+			for key, value := range defaultMetadata {
+				md[key] = value
+			}
+			if caseMetadata, ok := testCase[metadataJSONKey].(map[string]interface{}); ok {
+				for key, value := range caseMetadata {
+					md[key] = value
+				}
+			}
+			testCase[metadataJSONKey] = md
+		}
+	}
+	return scenario, options, nil
+}
+
+// mergeOptions merges the options of an included scenario file into those of the including one, which apply to the whole run.
+// require_healthy holds if it holds for either of them, the longer inter_case_delay_ms is taken, and the expected_action_counts are summed
+// since the included test cases are counted together with the others. default_metadata is already applied to the included test cases.
+func (runner *TestServiceTestRunner) mergeOptions(options, included map[string]interface{}) {
+	if v, ok := included[requireHealthyJSONKey].(bool); ok && v {
+		options[requireHealthyJSONKey] = true
+	}
+	if v, ok := included[interCaseDelayMsJSONKey].(float64); ok {
+		if delay, ok := options[interCaseDelayMsJSONKey].(float64); !ok || delay < v {
+			options[interCaseDelayMsJSONKey] = v
+		}
+	}
+	// The invalid values are kept instead of being summed, so that they are reported by the checks of expected_action_counts.
+	v, ok := included[expectedActionCountsJSONKey]
+	if !ok {
+		return
+	}
+	parent, parentIsObject := options[expectedActionCountsJSONKey].(map[string]interface{})
+	if _, ok := options[expectedActionCountsJSONKey]; ok && !parentIsObject {
+		return
+	}
+	includedCounts, ok := v.(map[string]interface{})
+	if !ok {
+		options[expectedActionCountsJSONKey] = v
+		return
+	}
+	counts := map[string]interface{}{}
+	for action, count := range parent {
+		counts[action] = count
+	}
+	for action, count := range includedCounts {
+		if sum, ok := counts[action].(float64); ok {
+			if includedCount, ok := count.(float64); ok {
+				counts[action] = sum + includedCount
+				continue
+			}
+		} else if _, ok := counts[action]; ok {
+			continue
+		}
+		counts[action] = count
+	}
+	options[expectedActionCountsJSONKey] = counts
+}
+
+// locationKey is the key of the test cases of the included scenario files which has their locations in the files,
+// such as child.json: the test case 0, by which their failures are reported instead of the indexes in the including scenario.
+const locationKey = "$location"
+
+// locateCases records the locations of the test cases of the scenario file jsonPath if it is included by another one.
+func (runner *TestServiceTestRunner) locateCases(jsonPath string, cases []map[string]interface{}, loading map[string]bool) {
+	if len(loading) < 2 {
+		return
+	}
+	for i, testCase := range cases {
+		testCase[locationKey] = fmt.Sprintf("%s: the test case %d", jsonPath, i)
+	}
+}
+
+// caseLocation returns the location of the test case at the index of the scenario file jsonPath,
+// which is the location in the included file if the test case is in one.
+func (runner *TestServiceTestRunner) caseLocation(jsonPath string, index int, testCase map[string]interface{}) string {
+	if location, ok := testCase[locationKey].(string); ok {
+		return location
+	}
+	return fmt.Sprintf("%s: the test case %d", jsonPath, index)
+}
+
+// readFile reads the file, which is decompressed by gzip if its name ends with .gz.
+func (runner *TestServiceTestRunner) readFile(path string) ([]byte, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasSuffix(path, ".gz") {
+		return data, nil
+	}
+	gzipReader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.ReadAll(gzipReader)
+}
+
+// loadRequestFiles replaces the request written as {"$file": "path"} with the request in the file,
+// whose path is relative to the scenario file.
+func (runner *TestServiceTestRunner) loadRequestFiles(jsonPath string, scenario []map[string]interface{}) error {
+	for _, testCase := range scenario {
+		req, ok := testCase[requestJSONKey].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		file, ok := req[fileJSONKey].(string)
+		if !ok {
+			continue
+		}
+		reqData, err := runner.readFile(filepath.Join(filepath.Dir(jsonPath), file))
+		if err != nil {
+			return err
+		}
+		var request interface{}
+		if err := json.Unmarshal(reqData, &request); err != nil {
+			return fmt.Errorf("Scenario JSON is invalid. Because the request file %s is not JSON: %v", file, err)
+		}
+		testCase[requestJSONKey] = request
+	}
+	return nil
+}
+
+// checkUnknownFields returns an error if the scenario has a key unknown to the runner, unless AllowUnknownFields is set.
+func (runner *TestServiceTestRunner) checkUnknownFields(scenario []map[string]interface{}, options map[string]interface{}) error {
+	if runner.AllowUnknownFields {
+		return nil
+	}
+	for key := range options {
+		if !scenarioTestServiceJSONKeys[key] {
+			return fmt.Errorf("Scenario JSON is invalid. Because %s is unknown.", key)
+		}
+	}
+	for i, testCase := range scenario {
+		for key := range testCase {
+			if !testCaseTestServiceJSONKeys[key] && key != locationKey {
+				return fmt.Errorf("Scenario JSON is invalid. Because %s of the test case %d is unknown.", key, i)
+			}
+		}
+	}
+	return nil
+}
+
+// deadlineExceededGrace is how long after the deadline the call asserted by assert_deadline_exceeded may return.
+const deadlineExceededGrace = 500 * time.Millisecond
+
+// defaultPollInterval is the interval of the calls of the test case with poll and of PollUntil when the interval is not given.
+const defaultPollInterval = 100 * time.Millisecond
+
+// poll calls invoke every interval until done holds for the response and the error of the call,
+// and returns the last response with an error if done does not hold within the timeout or when ctx is done.
+func (runner *TestServiceTestRunner) poll(ctx context.Context, name string, invoke func(ctx context.Context) (proto.Message, error), done func(res proto.Message, err error) bool, timeout, interval time.Duration) (proto.Message, error) {
+	if interval <= 0 {
+		interval = defaultPollInterval
+	}
+	deadline := runner.now().Add(timeout)
+	for calls := 1; ; calls++ {
+		res, err := invoke(ctx)
+		runner.countCall(name, err)
+		if done(res, err) {
+			return res, nil
+		}
+		if !runner.now().Add(interval).Before(deadline) {
+			return res, fmt.Errorf("the predicate over the responses of %s did not hold within %v after %d calls. The last error: %v", name, timeout, calls, err)
+		}
+		select {
+		case <-ctx.Done():
+			return res, fmt.Errorf("the polling of %s was aborted after %d calls: %v", name, calls, ctx.Err())
+		case <-runner.after(interval):
+		}
+	}
+}
+
+// defaultDeadlineTolerance is how much shorter than timeout_ms the remaining time echoed by the server may be in the deadline assertion,
+// which covers the time the request takes to reach the server.
+const defaultDeadlineTolerance = 100 * time.Millisecond
+
+const (
+	healthCheckTimeout  = 10 * time.Second
+	healthCheckInterval = 200 * time.Millisecond
+)
+
+// WaitHealthy polls the standard gRPC health service through the connection dialed by NewTestClientForTarget
+// until the server is SERVING, and returns an error if it is not SERVING within the timeout.
+func (runner *TestServiceTestRunner) WaitHealthy(ctx context.Context, timeout time.Duration) error {
+	if runner.conn == nil {
+		return errors.New("the health can not be checked without the connection dialed by NewTestClientForTarget")
+	}
+	ctx, cancel := runner.withTimeout(ctx, timeout)
+	defer cancel()
+	client := grpc_health_v1.NewHealthClient(runner.conn)
+	for {
+		res, err := client.Check(ctx, &grpc_health_v1.HealthCheckRequest{})
+		if err == nil && res.GetStatus() == grpc_health_v1.HealthCheckResponse_SERVING {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			if err != nil {
+				return fmt.Errorf("the server did not become healthy within %v: %v", timeout, err)
+			}
+			return fmt.Errorf("the server did not become healthy within %v: %v", timeout, res.GetStatus())
+		case <-runner.after(healthCheckInterval):
+		}
+	}
+}
+
+// TestServiceClock is the clock of TestServiceTestRunner, which can be replaced by a fake clock
+// in the tests of the sleeps, the delays and the timeouts of the runner.
+type TestServiceClock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// After returns a channel receiving the current time after the duration has elapsed.
+	After(d time.Duration) <-chan time.Time
+}
+
+// now returns the current time of Clock, or the real time if Clock is nil.
+func (runner *TestServiceTestRunner) now() time.Time {
+	if runner.Clock == nil {
+		return time.Now()
+	}
+	return runner.Clock.Now()
+}
+
+// after returns a channel receiving the current time after the duration has elapsed on Clock, or in the real time if Clock is nil.
+func (runner *TestServiceTestRunner) after(d time.Duration) <-chan time.Time {
+	if runner.Clock == nil {
+		return time.After(d)
+	}
+	return runner.Clock.After(d)
+}
+
+// sleep pauses for the duration on Clock, or in the real time if Clock is nil.
+func (runner *TestServiceTestRunner) sleep(d time.Duration) {
+	if d <= 0 {
+		return
+	}
+	if runner.Clock == nil {
+		time.Sleep(d)
+		return
+	}
+	<-runner.Clock.After(d)
+}
+
+// withTimeout returns the context done after the timeout as context.WithTimeout does. If Clock is set, the timeout elapses on Clock
+// and the context has no deadline of its own, because the deadline of a fake clock means nothing to the server.
+func (runner *TestServiceTestRunner) withTimeout(parent context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if runner.Clock == nil {
+		return context.WithTimeout(parent, timeout)
+	}
+	ctx := &clockTestServiceContext{Context: parent, done: make(chan struct{})}
+	stop := make(chan struct{})
+	timer := runner.Clock.After(timeout)
+	go func() {
+		select {
+		case <-timer:
+			ctx.cancel(context.DeadlineExceeded)
+		case <-parent.Done():
+			ctx.cancel(parent.Err())
+		case <-stop:
+		}
+	}()
+	var once sync.Once
+	return ctx, func() {
+		once.Do(func() { close(stop) })
+		ctx.cancel(context.Canceled)
+	}
+}
+
+// clockTestServiceContext is the context returned by withTimeout with Clock, which is done with the error given to cancel first.
+type clockTestServiceContext struct {
+	context.Context
+	done chan struct{}
+	mu   sync.Mutex
+	err  error
+}
+
+func (ctx *clockTestServiceContext) Done() <-chan struct{} {
+	return ctx.done
+}
+
+func (ctx *clockTestServiceContext) Err() error {
+	ctx.mu.Lock()
+	defer ctx.mu.Unlock()
+	return ctx.err
+}
+
+func (ctx *clockTestServiceContext) cancel(err error) {
+	ctx.mu.Lock()
+	defer ctx.mu.Unlock()
+	if ctx.err == nil {
+		ctx.err = err
+		close(ctx.done)
+	}
+}
+
+// baseContext returns the context from which the calls of a test are made.
+// It is BaseContext if set, or the context of t if t has Context() such as the TestServiceReporter returned by NewTestServiceTestingReporter.
+// Otherwise it is context.Background().
+func (runner *TestServiceTestRunner) baseContext(t TestServiceReporter) context.Context {
+	if runner.BaseContext != nil {
+		return runner.BaseContext
+	}
+	if tc, ok := t.(interface{ Context() context.Context }); ok {
+		return tc.Context()
+	}
+	return context.Background()
+}
+
+// variableReferencePattern is the pattern of ${name} referring to a variable.
+const variableReferencePattern = "\\$\\{([^}]+)\\}"
+
+// compilePattern returns the compiled regular expression of pattern. The regular expressions are compiled once
+// and cached in the runner under patternsMu instead of the package, so that the runners running concurrently share no state.
+func (runner *TestServiceTestRunner) compilePattern(pattern string) (*regexp.Regexp, error) {
+	runner.patternsMu.Lock()
+	defer runner.patternsMu.Unlock()
+	if re, ok := runner.patterns[pattern]; ok {
+		return re, nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	if runner.patterns == nil {
+		runner.patterns = map[string]*regexp.Regexp{}
+	}
+	runner.patterns[pattern] = re
+	return re, nil
+}
+
+// interpolate replaces each ${name} in str with the value of the captured variable name.
+// Unless captured, ${uuid} is replaced with a random UUID and ${random:int} with a random non-negative integer.
+func (runner *TestServiceTestRunner) interpolate(str string, variables map[string]interface{}) (string, error) {
+	var err error
+	variableReference, _ := runner.compilePattern(variableReferencePattern)
+	interpolated := variableReference.ReplaceAllStringFunc(str, func(ref string) string {
+		name := variableReference.FindStringSubmatch(ref)[1]
+		v, ok := variables[name]
+		if !ok {
+			v, ok = runner.randomToken(name, variables)
+		}
+		if !ok {
+			if err == nil {
+				err = fmt.Errorf("the variable %s is not captured", name)
+			}
+			return ref
+		}
+		return runner.formatVariable(v)
+	})
+	return interpolated, err
+}
+
+// formatVariable returns the string replacing a reference to the variable.
+func (runner *TestServiceTestRunner) formatVariable(v interface{}) string {
+	if f, ok := v.(float64); ok {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	return fmt.Sprint(v)
+}
+
+// SeedEnvKey is the name of the environment variable that has the seed of the random tokens such as ${uuid}.
+// If it is not set, the seed is based on the current time. The seed is logged when the test fails.
+const SeedEnvKey = "STEST_SEED"
+
+const (
+	randomVariable = "$random"
+	uuidToken      = "uuid"
+	randomIntToken = "random:int"
+)
+
+// newRandom stores the source of the random tokens into variables and returns its seed.
+func (runner *TestServiceTestRunner) newRandom(variables map[string]interface{}) (int64, error) {
+	seed := runner.now().UnixNano()
+	if v := os.Getenv(SeedEnvKey); v != "" {
+		var err error
+		if seed, err = strconv.ParseInt(v, 10, 64); err != nil {
+			return 0, fmt.Errorf("%s is not an integer: %v", SeedEnvKey, err)
+		}
+	}
+	variables[randomVariable] = mathrand.New(mathrand.NewSource(seed))
+	return seed, nil
+}
+
+// randomToken returns the value of the random token named name.
+func (runner *TestServiceTestRunner) randomToken(name string, variables map[string]interface{}) (string, bool) {
+	random, ok := variables[randomVariable].(*mathrand.Rand)
+	if !ok {
+		return "", false
+	}
+	switch name {
+	case uuidToken:
+		uuid := make([]byte, 16)
+		random.Read(uuid)
+		uuid[6] = uuid[6]&0x0f | 0x40
+		uuid[8] = uuid[8]&0x3f | 0x80
+		return fmt.Sprintf("%x-%x-%x-%x-%x", uuid[0:4], uuid[4:6], uuid[6:8], uuid[8:10], uuid[10:]), true
+	case randomIntToken:
+		return strconv.Itoa(int(random.Int31())), true
+	}
+	return "", false
+}
+
+// outgoingContext returns the context carrying the metadata of the test case.
+// The metadata values can refer to the captured variables by ${name}, and override the metadata of the same keys in ctx.
+func (runner *TestServiceTestRunner) outgoingContext(ctx context.Context, testCase map[string]interface{}, variables map[string]interface{}) (context.Context, error) {
+	v, ok := testCase[metadataJSONKey]
+	if !ok {
+		return ctx, nil
+	}
+	md := metadata.MD{}
+	if parent, ok := metadata.FromOutgoingContext(ctx); ok {
+		md = parent.Copy()
+	}
+	values := v.(map[string]interface{})
+	// The keys are sorted so that the random tokens are generated in the same order for the same seed.
+	for _, key := range runner.sortedKeys(values) {
+		interpolated, err := runner.interpolate(values[key].(string), variables)
+		if err != nil {
+			return nil, fmt.Errorf("the metadata %s can not be resolved: %v", key, err)
+		}
+		md.Set(key, interpolated)
+	}
+	return metadata.NewOutgoingContext(ctx, md), nil
+}
+
+// contextWithValues returns the context carrying the context_values of the test case by context.WithValue, whose keys are the strings,
+// so that the client interceptors and a client calling the server in process can read them.
+func (runner *TestServiceTestRunner) contextWithValues(ctx context.Context, testCase map[string]interface{}) context.Context {
+	v, ok := testCase[contextValuesJSONKey]
+	if !ok {
+		return ctx
+	}
+	values := v.(map[string]interface{})
+	for _, key := range runner.sortedKeys(values) {
+		ctx = context.WithValue(ctx, key, values[key])
+	}
+	return ctx
+}
+
+// withAuthority returns the gRPC method calling through the connection whose authority is the authority of the test case,
+// or method itself if the test case has no authority. The connection is dialed in the same way as the connection dialed
+// by NewTestClientForTarget with grpc.WithAuthority, which also sets the server name of TLS, and is reused by the test cases of the authority.
+func (runner *TestServiceTestRunner) withAuthority(method grpcTestServiceMethod, testCase map[string]interface{}) (grpcTestServiceMethod, error) {
+	v, ok := testCase[authorityJSONKey]
+	if !ok {
+		return method, nil
+	}
+	if runner.conn == nil {
+		return method, fmt.Errorf("the %s can not be set without the connection dialed by NewTestClientForTarget", authorityJSONKey)
+	}
+	authority := v.(string)
+	runner.authorityConnsMu.Lock()
+	defer runner.authorityConnsMu.Unlock()
+	conn, ok := runner.authorityConns[authority]
+	if !ok {
+		var err error
+		conn, err = grpc.Dial(runner.target, append(runner.dialOptions, grpc.WithAuthority(authority))...)
+		if err != nil {
+			return method, err
+		}
+		if runner.authorityConns == nil {
+			runner.authorityConns = map[string]*grpc.ClientConn{}
+		}
+		runner.authorityConns[authority] = conn
+	}
+	authorityRunner := &TestServiceTestRunner{Client: NewTestServiceClient(conn)}
+	authorityMethod, _ := authorityRunner.lookupMethod(method.name)
+	return authorityMethod, nil
+}
+
+// callOptions returns the gRPC call options written in the call_options of the test case.
+func (runner *TestServiceTestRunner) callOptions(testCase map[string]interface{}) ([]grpc.CallOption, error) {
+	v, ok := testCase[callOptionsJSONKey]
+	if !ok {
+		return nil, nil
+	}
+	var opts []grpc.CallOption
+	for name, value := range v.(map[string]interface{}) {
+		var valid bool
+		switch name {
+		case callOptionWaitForReady:
+			var waitForReady bool
+			if waitForReady, valid = value.(bool); valid {
+				opts = append(opts, grpc.WaitForReady(waitForReady))
+			}
+		case callOptionMaxRecvSize:
+			var size float64
+			if size, valid = value.(float64); valid {
+				opts = append(opts, grpc.MaxCallRecvMsgSize(int(size)))
+			}
+		case callOptionCompressor:
+			var compressor string
+			if compressor, valid = value.(string); valid {
+				opts = append(opts, grpc.UseCompressor(compressor))
+			}
+		default:
+			return nil, fmt.Errorf("the call option %s is unknown", name)
+		}
+		if !valid {
+			return nil, fmt.Errorf("the call option %s has an invalid value %v", name, value)
+		}
+	}
+	return opts, nil
+}
+
+// checkIndependent returns an error if the test case depends on or affects the other test cases,
+// because such a test case must run sequentially in the order of the scenario.
+func (runner *TestServiceTestRunner) checkIndependent(testCase map[string]interface{}) error {
+	if _, ok := testCase[captureJSONKey]; ok {
+		return fmt.Errorf("the test case can not run in parallel because it has %s", captureJSONKey)
+	}
+	if _, ok := runner.responseReference(testCase); ok {
+		return errors.New("the test case can not run in parallel because it refers to the response of another test case")
+	}
+	testCaseJSON, _ := json.Marshal(testCase)
+	variableReference, _ := runner.compilePattern(variableReferencePattern)
+	if ref := variableReference.FindSubmatch(testCaseJSON); ref != nil {
+		switch name := string(ref[1]); name {
+		case uuidToken, randomIntToken:
+			return fmt.Errorf("the test case can not run in parallel because it has ${%s}, which is generated from %s in the order of the test cases", name, SeedEnvKey)
+		default:
+			return fmt.Errorf("the test case can not run in parallel because it refers to the captured variable ${%s}", name)
+		}
+	}
+	return nil
+}
+
+// TraceEnvKey is the name of the environment variable that makes the runner send a traceparent header with each test case when it is 1.
+const TraceEnvKey = "STEST_TRACE"
+
+const traceparentMetadataKey = "traceparent"
+
+// newTraceparent returns a new sampled traceparent in the W3C Trace Context format.
+func (runner *TestServiceTestRunner) newTraceparent() string {
+	traceID := make([]byte, 16)
+	parentID := make([]byte, 8)
+	rand.Read(traceID)
+	rand.Read(parentID)
+	return fmt.Sprintf("00-%s-%s-01", hex.EncodeToString(traceID), hex.EncodeToString(parentID))
+}
+
+// selectFields returns a copy of the message in which only the fields named by paths are set.
+// A path is the field names separated by dots, such as profile.country for a nested field.
+func (runner *TestServiceTestRunner) selectFields(message proto.Message, paths []string) proto.Message {
+	selected := proto.Clone(message)
+	runner.keepFields(selected.ProtoReflect(), paths)
+	return selected
+}
+
+func (runner *TestServiceTestRunner) keepFields(message protoreflect.Message, paths []string) {
+	whole := map[string]bool{}
+	nested := map[string][]string{}
+	for _, path := range paths {
+		names := strings.SplitN(path, ".", 2)
+		if len(names) == 1 {
+			whole[names[0]] = true
+		} else {
+			nested[names[0]] = append(nested[names[0]], names[1])
+		}
+	}
+	var fields []protoreflect.FieldDescriptor
+	message.Range(func(fd protoreflect.FieldDescriptor, _ protoreflect.Value) bool {
+		fields = append(fields, fd)
+		return true
+	})
+	for _, fd := range fields {
+		name := string(fd.Name())
+		if whole[name] || whole[fd.JSONName()] {
+			continue
+		}
+		subpaths, ok := nested[name]
+		if !ok {
+			subpaths, ok = nested[fd.JSONName()]
+		}
+		if ok && fd.Message() != nil && !fd.IsList() && !fd.IsMap() {
+			runner.keepFields(message.Mutable(fd).Message(), subpaths)
+			continue
+		}
+		message.Clear(fd)
+	}
+}
+
+// responsesVariable is the key of variables which has the responses of the sequential test cases in the order of execution.
+// The response of a test case is nil until its call succeeds.
+const responsesVariable = "$responses"
+
+// responseReferencePattern is the pattern of the reference to the response of a test case such as responses[0].
+const responseReferencePattern = "^responses\\[([0-9]+)\\]$"
+
+// recordResponse stores the response as that of the test case, unless the test case runs in parallel.
+func (runner *TestServiceTestRunner) recordResponse(testCase map[string]interface{}, response proto.Message, variables map[string]interface{}) {
+	if v, ok := testCase[parallelJSONKey]; ok && v.(bool) {
+		return
+	}
+	responses := variables[responsesVariable].([]proto.Message)
+	responses[len(responses)-1] = response
+}
+
+// responseReference returns the $ref of the expected_response of the test case, such as responses[0].
+func (runner *TestServiceTestRunner) responseReference(testCase map[string]interface{}) (string, bool) {
+	expectedRes, ok := testCase[expectedResponseJSONKey].(map[string]interface{})
+	if !ok {
+		return "", false
+	}
+	ref, ok := expectedRes[refJSONKey].(string)
+	return ref, ok
+}
+
+// referredResponse returns the response referred to by ref.
+// responses[i] is the response of the i-th sequential test case counted from 0, which must have run before.
+func (runner *TestServiceTestRunner) referredResponse(ref string, variables map[string]interface{}) (proto.Message, error) {
+	responseReference, _ := runner.compilePattern(responseReferencePattern)
+	match := responseReference.FindStringSubmatch(ref)
+	if match == nil {
+		return nil, fmt.Errorf("the reference %s of the expected response is invalid", ref)
+	}
+	index, _ := strconv.Atoi(match[1])
+	responses, _ := variables[responsesVariable].([]proto.Message)
+	if index >= len(responses)-1 {
+		return nil, fmt.Errorf("the test case referred to by %s has not run before", ref)
+	}
+	if responses[index] == nil {
+		return nil, fmt.Errorf("the test case referred to by %s has no response", ref)
+	}
+	return responses[index], nil
+}
+
+// extractMatchers returns the expected response written in JSON without the matchers such as {"$gte": 1},
+// and stores the matchers into matchers by the dot separated path of the field.
+func (runner *TestServiceTestRunner) extractMatchers(expected interface{}, prefix string, matchers map[string]map[string]interface{}) interface{} {
+	object, ok := expected.(map[string]interface{})
+	if !ok {
+		return expected
+	}
+	stripped := map[string]interface{}{}
+	for key, value := range object {
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+		if matcher, ok := value.(map[string]interface{}); ok && runner.isMatcher(matcher) {
+			matchers[path] = matcher
+			continue
+		}
+		stripped[key] = runner.extractMatchers(value, path, matchers)
+	}
+	return stripped
+}
+
+// isMatcher reports whether every key of the object is an operator such as $gte.
+func (runner *TestServiceTestRunner) isMatcher(object map[string]interface{}) bool {
+	if len(object) == 0 {
+		return false
+	}
+	for key := range object {
+		if !strings.HasPrefix(key, "$") {
+			return false
+		}
+	}
+	return true
+}
+
+// checkMatchers returns an error if a field of the response does not satisfy its matcher.
+func (runner *TestServiceTestRunner) checkMatchers(name string, response proto.Message, matchers map[string]map[string]interface{}) error {
+	for path, matcher := range matchers {
+		value, err := runner.fieldValue(response.ProtoReflect(), path)
+		if err != nil {
+			return err
+		}
+		for operator, operand := range matcher {
+			if operator == operatorAll {
+				if err := runner.checkAll(name, path, value, operand); err != nil {
+					return err
+				}
+				continue
+			}
+			ok, err := runner.match(operator, value, operand)
+			if err != nil {
+				return fmt.Errorf("the %s of the field %s is invalid: %v", operator, path, err)
+			}
+			if !ok {
+				return fmt.Errorf("the field %s of the response of the %s was %v, which does not satisfy %s %v", path, name, value, operator, operand)
+			}
+		}
+	}
+	return nil
+}
+
+// checkAll returns an error if an element of the repeated field of the path does not satisfy the matcher of $all,
+// such as {"$all": {"$regex": "^[0-9a-f]{32}$"}} for the list of the ids.
+func (runner *TestServiceTestRunner) checkAll(name, path string, value, operand interface{}) error {
+	list, ok := value.(protoreflect.List)
+	matcher, isObject := operand.(map[string]interface{})
+	if !ok || !isObject || !runner.isMatcher(matcher) {
+		return fmt.Errorf("the %s of the field %s is invalid: it applies a matcher to the elements of a repeated field", operatorAll, path)
+	}
+	for i := 0; i < list.Len(); i++ {
+		element := list.Get(i).Interface()
+		for operator, elementOperand := range matcher {
+			ok, err := runner.match(operator, element, elementOperand)
+			if err != nil {
+				return fmt.Errorf("the %s of the field %s is invalid: %v", operator, path, err)
+			}
+			if !ok {
+				return fmt.Errorf("the field %s[%d] of the response of the %s was %v, which does not satisfy %s %v", path, i, name, element, operator, elementOperand)
+			}
+		}
+	}
+	return nil
+}
+
+// checkUnsetFields returns an error if a field named by the dot separated paths is set in the response.
+// A scalar field without presence is unset if it is the zero value, and a repeated or map field is unset if it is empty.
+func (runner *TestServiceTestRunner) checkUnsetFields(name string, response proto.Message, paths []string) error {
+	for _, path := range paths {
+		message := response.ProtoReflect()
+		names := strings.Split(path, ".")
+		for i, fieldName := range names {
+			fd := runner.fieldByName(message, fieldName)
+			if fd == nil || i < len(names)-1 && (fd.Message() == nil || fd.IsList() || fd.IsMap()) {
+				return fmt.Errorf("the field %s is not in the response", path)
+			}
+			if !message.Has(fd) {
+				break
+			}
+			if i == len(names)-1 {
+				return fmt.Errorf("the field %s of the response of the %s was set to %v, which must be unset", path, name, message.Get(fd).Interface())
+			}
+			message = message.Get(fd).Message()
+		}
+	}
+	return nil
+}
+
+// match reports whether the value of a field satisfies the operator with the operand.
+func (runner *TestServiceTestRunner) match(operator string, value, operand interface{}) (bool, error) {
+	switch operator {
+	case operatorGt, operatorGte, operatorLt, operatorLte:
+		actual, ok := runner.number(value)
+		expected, isNumber := operand.(float64)
+		if !ok || !isNumber {
+			return false, errors.New("it compares only numbers")
+		}
+		switch operator {
+		case operatorGt:
+			return actual > expected, nil
+		case operatorGte:
+			return actual >= expected, nil
+		case operatorLt:
+			return actual < expected, nil
+		default:
+			return actual <= expected, nil
+		}
+	case operatorNe:
+		if actual, ok := runner.number(value); ok {
+			expected, isNumber := operand.(float64)
+			if !isNumber {
+				return false, errors.New("the field is a number")
+			}
+			return actual != expected, nil
+		}
+		return !reflect.DeepEqual(value, operand), nil
+	case operatorRegex:
+		actual, ok := value.(string)
+		pattern, isString := operand.(string)
+		if !ok || !isString {
+			return false, errors.New("it matches only strings")
+		}
+		re, err := runner.compilePattern(pattern)
+		if err != nil {
+			return false, err
+		}
+		return re.MatchString(actual), nil
+	default:
+		return false, errors.New("the operator is unknown")
+	}
+}
+
+// number returns the numeric value of a field as float64.
+func (runner *TestServiceTestRunner) number(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	case protoreflect.EnumNumber:
+		return float64(v), true
+	}
+	return 0, false
+}
+
+// fieldByName returns the field of the message named by the field name or the JSON name.
+func (runner *TestServiceTestRunner) fieldByName(message protoreflect.Message, name string) protoreflect.FieldDescriptor {
+	fields := message.Descriptor().Fields()
+	if fd := fields.ByName(protoreflect.Name(name)); fd != nil {
+		return fd
+	}
+	return fields.ByJSONName(name)
+}
+
+// fieldValue returns the value of the field of the message named by the dot separated path.
+func (runner *TestServiceTestRunner) fieldValue(message protoreflect.Message, path string) (interface{}, error) {
+	names := strings.Split(path, ".")
+	for i, name := range names {
+		fd := runner.fieldByName(message, name)
+		if fd == nil {
+			break
+		}
+		if i == len(names)-1 {
+			return message.Get(fd).Interface(), nil
+		}
+		if fd.Message() == nil || fd.IsList() || fd.IsMap() {
+			break
+		}
+		message = message.Get(fd).Message()
+	}
+	return nil, fmt.Errorf("the field %s is not in the response", path)
+}
+
+// clearFields returns a copy of the message in which the fields of the dot separated paths, such as those of the matchers, are cleared
+// so that they are not compared with the expected response.
+func (runner *TestServiceTestRunner) clearFields(message proto.Message, paths []string) proto.Message {
+	if len(paths) == 0 {
+		return message
+	}
+	cleared := proto.Clone(message)
+	for _, path := range paths {
+		current := cleared.ProtoReflect()
+		names := strings.Split(path, ".")
+		for i, name := range names {
+			fd := runner.fieldByName(current, name)
+			if fd == nil {
+				break
+			}
+			if i == len(names)-1 {
+				current.Clear(fd)
+			} else if current.Has(fd) && fd.Message() != nil && !fd.IsList() && !fd.IsMap() {
+				current = current.Mutable(fd).Message()
+			} else {
+				break
+			}
+		}
+	}
+	return cleared
+}
+
+// capture stores the fields of the response named by the capture of the test case into variables.
+// The capture takes a variable name as a key and value has a dot separated path of the JSON field names of the response.
+func (runner *TestServiceTestRunner) capture(testCase map[string]interface{}, response interface{}, variables map[string]interface{}) error {
+	v, ok := testCase[captureJSONKey]
+	if !ok {
+		return nil
+	}
+	resJSON, err := json.Marshal(response)
+	if err != nil {
+		return err
+	}
+	var res interface{}
+	json.Unmarshal(resJSON, &res)
+	for name, path := range v.(map[string]interface{}) {
+		value := res
+		for _, field := range strings.Split(path.(string), ".") {
+			switch current := value.(type) {
+			case map[string]interface{}:
+				value, ok = current[field]
+			case []interface{}:
+				index, indexErr := strconv.Atoi(field)
+				ok = indexErr == nil && index >= 0 && index < len(current)
+				if ok {
+					value = current[index]
+				}
+			default:
+				ok = false
+			}
+			if !ok {
+				return fmt.Errorf("the field %s to capture as %s is not in the response", path, name)
+			}
+		}
+		variables[name] = value
+	}
+	return nil
+}
+
+const (
+	actionJSONKey                 = "action"
+	requestJSONKey                = "request"
+	expectedResponseJSONKey       = "expected_response"
+	errorExpectationJSONKey       = "error_expectation"
+	expectedErrorCodeJSONKey      = "expected_error_code"
+	loopJSONKey                   = "loop"
+	sleepJSONKey                  = "sleep"
+	successRuleJSONKey            = "success_rule"
+	successRuleAll                = "all"
+	successRuleOnce               = "once"
+	responseFormatJSONKey         = "response_format"
+	responseFormatJSON            = "json"
+	responseFormatPrototext       = "prototext"
+	metadataJSONKey               = "metadata"
+	captureJSONKey                = "capture"
+	casesJSONKey                  = "cases"
+	defaultMetadataJSONKey        = "default_metadata"
+	parallelJSONKey               = "parallel"
+	requireHealthyJSONKey         = "require_healthy"
+	interCaseDelayMsJSONKey       = "inter_case_delay_ms"
+	delayBeforeMsJSONKey          = "delay_before_ms"
+	includeJSONKey                = "include"
+	assertFieldsJSONKey           = "assert_fields"
+	expectedUnsetFieldsJSONKey    = "expected_unset_fields"
+	forbiddenErrorCodeJSONKey     = "forbidden_error_code"
+	callOptionsJSONKey            = "call_options"
+	callOptionWaitForReady        = "wait_for_ready"
+	callOptionMaxRecvSize         = "max_recv_size"
+	callOptionCompressor          = "compressor"
+	timeoutMsJSONKey              = "timeout_ms"
+	pollJSONKey                   = "poll"
+	intervalMsJSONKey             = "interval_ms"
+	assertDeadlineExceededJSONKey = "assert_deadline_exceeded"
+	validateRequestJSONKey        = "validate_request"
+	assertionsJSONKey             = "assertions"
+	maxResponseBytesJSONKey       = "max_response_bytes"
+	errorJSONKey                  = "error"
+	errorCodeJSONKey              = "code"
+	errorMessageContainsJSONKey   = "message_contains"
+	errorDetailsJSONKey           = "details"
+	anyTypeJSONKey                = "@type"
+	assertionTypeJSONKey          = "type"
+	assertionTypeResponse         = "response"
+	assertionTypeError            = "error"
+	assertionTypeHeader           = "header"
+	assertionTypeTrailer          = "trailer"
+	assertionTypeLatency          = "latency"
+	assertionTypeDeadline         = "deadline"
+	expectedHeaderJSONKey         = "expected_header"
+	expectedTrailerJSONKey        = "expected_trailer"
+	maxLatencyMsJSONKey           = "max_latency_ms"
+	fieldJSONKey                  = "field"
+	toleranceMsJSONKey            = "tolerance_ms"
+	refJSONKey                    = "$ref"
+	fileJSONKey                   = "$file"
+	idJSONKey                     = "id"
+	templateJSONKey               = "template"
+	authorityJSONKey              = "authority"
+	expectedStatusJSONKey         = "expected_status"
+	contextValuesJSONKey          = "context_values"
+	expectedActionCountsJSONKey   = "expected_action_counts"
+	nameJSONKey                   = "name"
+	matrixJSONKey                 = "matrix"
+	expectedErrorReasonJSONKey    = "expected_error_reason"
+	expectedPeerRegexJSONKey      = "expected_peer_regex"
+	operatorGt                    = "$gt"
+	operatorGte                   = "$gte"
+	operatorLt                    = "$lt"
+	operatorLte                   = "$lte"
+	operatorNe                    = "$ne"
+	operatorRegex                 = "$regex"
+	operatorAll                   = "$all"
+	expectationExact              = "$exact"
+	expectationSubset             = "$subset"
+	expectationAnyOf              = "$anyOf"
+	expectationSchema             = "$schema"
+	expectationEchoRequest        = "$echoRequest"
+)
+
+// The keywords of the JSON Schema of $schema.
+const (
+	schemaType                 = "type"
+	schemaProperties           = "properties"
+	schemaRequired             = "required"
+	schemaAdditionalProperties = "additionalProperties"
+	schemaItems                = "items"
+	schemaEnum                 = "enum"
+	schemaConst                = "const"
+	schemaPattern              = "pattern"
+	schemaMinLength            = "minLength"
+	schemaMaxLength            = "maxLength"
+	schemaMinimum              = "minimum"
+	schemaMaximum              = "maximum"
+	schemaMinItems             = "minItems"
+	schemaMaxItems             = "maxItems"
+)
+
+// scenarioTestServiceJSONKeys are the keys allowed in the scenario object other than cases.
+var scenarioTestServiceJSONKeys = map[string]bool{
+	defaultMetadataJSONKey:      true,
+	requireHealthyJSONKey:       true,
+	interCaseDelayMsJSONKey:     true,
+	expectedActionCountsJSONKey: true,
+}
+
+// testCaseTestServiceJSONKeys are the keys allowed in a test case.
+var testCaseTestServiceJSONKeys = map[string]bool{
+	actionJSONKey:                 true,
+	requestJSONKey:                true,
+	expectedResponseJSONKey:       true,
+	errorExpectationJSONKey:       true,
+	expectedErrorCodeJSONKey:      true,
+	forbiddenErrorCodeJSONKey:     true,
+	loopJSONKey:                   true,
+	sleepJSONKey:                  true,
+	successRuleJSONKey:            true,
+	responseFormatJSONKey:         true,
+	metadataJSONKey:               true,
+	captureJSONKey:                true,
+	parallelJSONKey:               true,
+	delayBeforeMsJSONKey:          true,
+	assertFieldsJSONKey:           true,
+	expectedUnsetFieldsJSONKey:    true,
+	callOptionsJSONKey:            true,
+	timeoutMsJSONKey:              true,
+	pollJSONKey:                   true,
+	assertDeadlineExceededJSONKey: true,
+	validateRequestJSONKey:        true,
+	assertionsJSONKey:             true,
+	maxResponseBytesJSONKey:       true,
+	errorJSONKey:                  true,
+	idJSONKey:                     true,
+	templateJSONKey:               true,
+	authorityJSONKey:              true,
+	expectedStatusJSONKey:         true,
+	contextValuesJSONKey:          true,
+	nameJSONKey:                   true,
+	matrixJSONKey:                 true,
+	expectedErrorReasonJSONKey:    true,
+	expectedPeerRegexJSONKey:      true,
+}
+
+// runTest runs the test case at the index of the scenario file jsonPath as a subtest, whose failures are prefixed with them.
+// jsonPath is empty for the test case given to RunCase, which is not in a file.
+func (runner *TestServiceTestRunner) runTest(ctx context.Context, t TestServiceReporter, jsonPath string, index int, testCase map[string]interface{}, compareFuncMap map[string]*func(expectedResponse, response interface{}) error, variables map[string]interface{}) {
+	var action string
+	if v, ok := testCase[actionJSONKey]; ok {
+		action = v.(string)
+	} else {
+		panic("Scenario JSON is invalid. Because action is required.")
+	}
+	parallel := false
+	if v, ok := testCase[parallelJSONKey]; ok {
+		parallel = v.(bool)
+	}
+	delayBefore := 0
+	if v, ok := testCase[delayBeforeMsJSONKey]; ok {
+		delayBefore = int(v.(float64))
+	}
+	if !parallel {
+		responses, _ := variables[responsesVariable].([]proto.Message)
+		variables[responsesVariable] = append(responses, nil)
+	}
+	f := func(t TestServiceReporter) {
+		_, propagatesPanics := t.(panicTestServicePropagator)
+		if jsonPath != "" {
+			t = &caseTestServiceReporter{t, runner.caseLocation(jsonPath, index, testCase)}
+		}
+		if !runner.PropagatePanics && !propagatesPanics {
+			defer runner.recoverCase(t)
+		}
+		if runner.SkipUnless != nil && !runner.SkipUnless(action) {
+			t.Skip("the " + action + " is skipped because SkipUnless returned false")
+		}
+		if parallel {
+			if err := runner.checkIndependent(testCase); err != nil {
+				t.Fatalf("%v", err)
+			}
+			t.Parallel()
+		}
+		delay := time.Duration(delayBefore) * time.Millisecond
+		runOptions, _ := variables[runOptionsVariable].(TestServiceRunOptions)
+		replay := runOptions.Replay && !parallel
+		if start, ok := variables[replayStartVariable].(time.Time); ok && replay {
+			delay -= runner.now().Sub(start)
+		}
+		runner.sleep(delay)
+		if replay {
+			variables[replayStartVariable] = runner.now()
+		}
+		switch action {
+		case "Hello":
+			compareFunc := compareFuncMap["Hello"]
+			runner.testHello(ctx, t, testCase, compareFunc, variables)
+		case "Bye":
+			compareFunc := compareFuncMap["Bye"]
+			runner.testBye(ctx, t, testCase, compareFunc, variables)
+		}
+	}
+	name := action
+	if v, ok := testCase[nameJSONKey]; ok {
+		name = v.(string)
+	}
+	t.Run(name, f)
+}
+
+// panicTestServicePropagator is implemented by the Reporters whose Fatalf and Skip panic to stop the test case, such as that of Ginkgo.
+// Their test cases are run without recovering the panics regardless of PropagatePanics, so that the panics reach the framework.
+type panicTestServicePropagator interface {
+	propagatesPanics()
+}
+
+// recoverCase fails the test case with the stack of the panic instead of crashing the test binary.
+func (runner *TestServiceTestRunner) recoverCase(t TestServiceReporter) {
+	if r := recover(); r != nil {
+		t.Errorf("the test case panicked: %v\n%s", r, debug.Stack())
+	}
+}
+
+// caseTestServiceReporter is the TestServiceReporter of a test case of a scenario file, which prefixes the failures with the location of the test case
+// so that they can be traced back to the scenario in the logs of a large run.
+type caseTestServiceReporter struct {
+	TestServiceReporter
+	location string
+}
+
+func (t *caseTestServiceReporter) Run(name string, f func(t TestServiceReporter)) bool {
+	return t.TestServiceReporter.Run(name, func(sub TestServiceReporter) {
+		f(&caseTestServiceReporter{sub, t.location})
+	})
+}
+
+func (t *caseTestServiceReporter) Fatalf(format string, args ...interface{}) {
+	t.TestServiceReporter.Fatalf("%s: %s", t.location, fmt.Sprintf(format, args...))
+}
+
+func (t *caseTestServiceReporter) Errorf(format string, args ...interface{}) {
+	t.TestServiceReporter.Errorf("%s: %s", t.location, fmt.Sprintf(format, args...))
+}
+
+// grpcTestServiceMethod defines how to build the messages of a gRPC method and how to call it.
+type grpcTestServiceMethod struct {
+	name        string
+	newRequest  func() proto.Message
+	newResponse func() proto.Message
+	invoke      func(ctx context.Context, req proto.Message, opts ...grpc.CallOption) (proto.Message, error)
+}
+
+// lookupMethod returns the gRPC method named action.
+func (runner *TestServiceTestRunner) lookupMethod(action string) (grpcTestServiceMethod, bool) {
+	switch action {
+	case "Hello":
+		return runner.methodHello(), true
+	case "Bye":
+		return runner.methodBye(), true
+	}
+	return grpcTestServiceMethod{}, false
+}
+
+func (runner *TestServiceTestRunner) testMethod(ctx context.Context, t TestServiceReporter, testCase map[string]interface{}, compareFunc *func(expectedResponse, response interface{}) error, variables map[string]interface{}, method grpcTestServiceMethod) {
+	if runner.LogConnectionState {
+		defer func() {
+			if t.Failed() {
+				t.Logf("the state of the connections:\n%s", runner.ConnectionState())
+			}
+		}()
+	}
+	method, methodErr := runner.withAuthority(method, testCase)
+	if methodErr != nil {
+		t.Fatalf("%v", methodErr)
+	}
+	ctx, ctxErr := runner.outgoingContext(ctx, testCase, variables)
+	if ctxErr != nil {
+		t.Fatalf("%v", ctxErr)
+	}
+	ctx = runner.contextWithValues(ctx, testCase)
+	callOpts, optsErr := runner.callOptions(testCase)
+	if optsErr != nil {
+		t.Fatalf("%v", optsErr)
+	}
+	if os.Getenv(TraceEnvKey) == "1" {
+		traceparent := runner.newTraceparent()
+		ctx = metadata.AppendToOutgoingContext(ctx, traceparentMetadataKey, traceparent)
+		defer func() {
+			if t.Failed() {
+				t.Logf("%s: %s", traceparentMetadataKey, traceparent)
+			}
+		}()
+	}
+	req := method.newRequest()
+	if err := runner.buildRequest(testCase[requestJSONKey], req); err != nil {
+		t.Fatalf("the request of the %s can not be built: %v", method.name, err)
+	}
+	if v, ok := testCase[validateRequestJSONKey]; ok && v.(bool) {
+		// The request is validated only if it has Validate() such as the one generated by protoc-gen-validate.
+		if validator, ok := req.(interface{ Validate() error }); ok {
+			if err := validator.Validate(); err != nil {
+				t.Fatalf("the request of the %s is invalid: %v", method.name, err)
+			}
+		}
+	}
+
+	loop := 1
+	if v, ok := testCase[loopJSONKey]; ok {
+		loop = int(v.(float64))
+	}
+	timeout := time.Duration(0)
+	if v, ok := testCase[timeoutMsJSONKey]; ok {
+		timeout = time.Duration(v.(float64)) * time.Millisecond
+	}
+	assertDeadlineExceeded := false
+	if v, ok := testCase[assertDeadlineExceededJSONKey]; ok {
+		assertDeadlineExceeded = v.(bool)
+	}
+	if assertDeadlineExceeded && timeout <= 0 {
+		t.Fatalf("%s of the test case requires %s\n", assertDeadlineExceededJSONKey, timeoutMsJSONKey)
+	}
+	successRule := successRuleAll
+	if v, ok := testCase[successRuleJSONKey]; ok {
+		successRule = v.(string)
+	}
+	// The test case with poll repeats the call every interval until it succeeds, as success_rule once without the limit of loop.
+	polling := false
+	var pollTimeout, pollInterval time.Duration
+	var pollDeadline time.Time
+	if v, ok := testCase[pollJSONKey]; ok {
+		options := v.(map[string]interface{})
+		if v, ok := options[timeoutMsJSONKey]; ok {
+			pollTimeout = time.Duration(v.(float64)) * time.Millisecond
+		}
+		if pollTimeout <= 0 {
+			t.Fatalf("%s of the test case requires %s\n", pollJSONKey, timeoutMsJSONKey)
+		}
+		pollInterval = defaultPollInterval
+		if v, ok := options[intervalMsJSONKey]; ok {
+			pollInterval = time.Duration(v.(float64)) * time.Millisecond
+		}
+		polling = true
+		successRule = successRuleOnce
+		pollDeadline = runner.now().Add(pollTimeout)
+	}
+FOR_LABEL:
+	for i := 1; polling || i <= loop; i++ {
+		sleep := 0
+		if v, ok := testCase[sleepJSONKey]; ok {
+			sleep = int(v.(float64))
+		}
+		runner.sleep(time.Duration(sleep) * time.Second)
+
+		callCtx := ctx
+		cancel := func() {}
+		if timeout > 0 {
+			callCtx, cancel = runner.withTimeout(ctx, timeout)
+		}
+		start := runner.now()
+		var header, trailer metadata.MD
+		callPeer := &peer.Peer{}
+		var encoding string
+		callCtx = context.WithValue(callCtx, encodingTestServiceKey{}, &encoding)
+		res, callErr := method.invoke(callCtx, req, append(callOpts, grpc.Header(&header), grpc.Trailer(&trailer), grpc.Peer(callPeer))...)
+		elapsed := runner.now().Sub(start)
+		cancel()
+		if encoding != "" {
+			if header == nil {
+				header = metadata.MD{}
+			}
+			header.Set(encodingMetadataKey, encoding)
+		}
+		runner.countCall(method.name, callErr)
+		runner.countAction(method.name, variables)
+		if err := runner.compareClients(ctx, method.name, req, callOpts, timeout, res, callErr); err != nil {
+			t.Errorf("%v", err)
+		}
+		runner.snapshotOutcome(testCase, method.name, res, callErr, variables)
+		if callErr == nil {
+			if captureErr := runner.capture(testCase, res, variables); captureErr != nil {
+				t.Fatalf("%v", captureErr)
+			}
+			runner.recordResponse(testCase, res, variables)
+		}
+		if captured, ok := variables[capturedCasesVariable].(*[]TestServiceCapturedCase); ok {
+			capturedCase := TestServiceCapturedCase{Name: method.name, Err: callErr}
+			if v, ok := testCase[nameJSONKey]; ok {
+				capturedCase.Name = v.(string)
+			}
+			if callErr == nil {
+				capturedCase.Response = res
+			}
+			*captured = append(*captured, capturedCase)
+			return
+		}
+		if os.Getenv(RecordEnvKey) != "" {
+			runner.recordOutcome(testCase, res, callErr)
+			return
+		}
+
+		var err error
+		errExpectation := assertDeadlineExceeded || runner.expectsError(testCase)
+		if v, ok := testCase[assertionsJSONKey]; ok {
+			err = runner.checkAssertions(method, v.([]interface{}), req, res, header, trailer, callErr, elapsed, timeout, compareFunc, variables)
+		} else if errExpectation {
+			if assertDeadlineExceeded {
+				if status.Code(callErr) != codes.DeadlineExceeded {
+					t.Fatalf("the error code of the response of %s is not as expected. Expected: %d, Actual: %d\n", method.name, codes.DeadlineExceeded, status.Code(callErr))
+				}
+				if elapsed > timeout+deadlineExceededGrace {
+					t.Fatalf("the call of %s returned %v after the deadline of %v\n", method.name, elapsed-timeout, timeout)
+				}
+			}
+			err = runner.checkError(method.name, testCase, callErr)
+			if err == nil {
+				err = runner.checkPeer(method.name, testCase, callPeer)
+			}
+			if !polling {
+				if err != nil {
+					t.Fatalf("%v", err)
+				}
+				break FOR_LABEL
+			}
+		} else {
+			err = runner.checkResponse(method, testCase, req, res, callErr, compareFunc, variables)
+		}
+		if err == nil && !errExpectation {
+			err = runner.checkPeer(method.name, testCase, callPeer)
+		}
+
+		switch successRule {
+		case successRuleAll:
+			if err != nil {
+				t.Fatalf("%v", err)
+			}
+		case successRuleOnce:
+			if polling {
+				if err == nil {
+					break FOR_LABEL
+				}
+				if !runner.now().Add(pollInterval).Before(pollDeadline) {
+					t.Fatalf("%v\nthe %s did not succeed within the %s timeout %v after %d calls", err, method.name, pollJSONKey, pollTimeout, i)
+				}
+				runner.sleep(pollInterval)
+				continue
+			}
+			if i == loop && err != nil {
+				t.Fatalf("%v", err)
+			}
+			if err == nil {
+				break FOR_LABEL
+			}
+		}
+	}
+}
+
+// compareClients calls the gRPC method with the request by each of CompareClients, and returns an error listing the clients
+// whose responses or errors differ from res and callErr returned by Client.
+func (runner *TestServiceTestRunner) compareClients(ctx context.Context, name string, req proto.Message, callOpts []grpc.CallOption, timeout time.Duration, res proto.Message, callErr error) error {
+	var divergences []string
+	for i, client := range runner.CompareClients {
+		method, _ := (&TestServiceTestRunner{Client: client}).lookupMethod(name)
+		callCtx, cancel := ctx, func() {}
+		if timeout > 0 {
+			callCtx, cancel = runner.withTimeout(ctx, timeout)
+		}
+		otherRes, otherErr := method.invoke(callCtx, req, callOpts...)
+		cancel()
+		expected, actual := status.Convert(callErr), status.Convert(otherErr)
+		switch {
+		case expected.Code() != actual.Code() || expected.Message() != actual.Message():
+			divergences = append(divergences, fmt.Sprintf("the client %d returned the error %v, but Client returned %v", i, otherErr, callErr))
+		case callErr == nil && !proto.Equal(res, otherRes):
+			resJSON, _ := runner.marshalMessage(res)
+			otherJSON, _ := runner.marshalMessage(otherRes)
+			divergences = append(divergences, fmt.Sprintf("the client %d returned %s, but Client returned %s", i, otherJSON, resJSON))
+		}
+	}
+	if len(divergences) > 0 {
+		return fmt.Errorf("the clients diverged on %s:\n%s", name, strings.Join(divergences, "\n"))
+	}
+	return nil
+}
+
+// checkPeer returns an error if the address of the peer which handled the call does not match the expected_peer_regex of the test case,
+// such as the backend chosen by a load balancer.
+func (runner *TestServiceTestRunner) checkPeer(name string, testCase map[string]interface{}, callPeer *peer.Peer) error {
+	v, ok := testCase[expectedPeerRegexJSONKey]
+	if !ok {
+		return nil
+	}
+	re, err := runner.compilePattern(v.(string))
+	if err != nil {
+		return fmt.Errorf("the %s is invalid: %v", expectedPeerRegexJSONKey, err)
+	}
+	if callPeer.Addr == nil {
+		return fmt.Errorf("the peer of the call of %s is unknown", name)
+	}
+	if address := callPeer.Addr.String(); !re.MatchString(address) {
+		return fmt.Errorf("the call of %s was handled by the peer %s, which does not match %q", name, address, v)
+	}
+	return nil
+}
+
+// expectsError reports whether the test case expects an error response by the error_expectation, the error object,
+// the expected_status or the expected_error_reason.
+func (runner *TestServiceTestRunner) expectsError(testCase map[string]interface{}) bool {
+	if _, ok := testCase[errorJSONKey]; ok {
+		return true
+	}
+	if _, ok := testCase[expectedStatusJSONKey]; ok {
+		return true
+	}
+	if _, ok := testCase[expectedErrorReasonJSONKey]; ok {
+		return true
+	}
+	v, ok := testCase[errorExpectationJSONKey]
+	return ok && v.(bool)
+}
+
+// checkError returns an error if the call did not fail as expected by the error object, the expected_status, the expected_error_reason,
+// the expected_error_code and the forbidden_error_code of spec, which is a test case or an assertion.
+// The expected_error_code is either a code or an array of the acceptable codes.
+func (runner *TestServiceTestRunner) checkError(name string, spec map[string]interface{}, callErr error) error {
+	if v, ok := spec[errorJSONKey]; ok {
+		if err := runner.checkStatus(name, v.(map[string]interface{}), callErr); err != nil {
+			return err
+		}
+	}
+	if v, ok := spec[expectedStatusJSONKey]; ok {
+		expected, err := runner.parseStatus(v)
+		if err != nil {
+			return fmt.Errorf("the %s is invalid: %v", expectedStatusJSONKey, err)
+		}
+		if callErr == nil {
+			return fmt.Errorf("the response of %s is not an error as expected", name)
+		}
+		if actual := status.Convert(callErr).Proto(); !proto.Equal(expected, actual) {
+			expectedJSON, _ := protojson.Marshal(expected)
+			actualJSON, _ := protojson.Marshal(actual)
+			return fmt.Errorf("the status of the error of the response of %s is not as expected. Expected: %s, Actual: %s", name, expectedJSON, actualJSON)
+		}
+	}
+	if v, ok := spec[expectedErrorReasonJSONKey]; ok {
+		if callErr == nil {
+			return fmt.Errorf("the response of %s is not an error as expected", name)
+		}
+		var reasons []string
+		for _, detail := range status.Convert(callErr).Details() {
+			if errorInfo, ok := detail.(*errdetails.ErrorInfo); ok {
+				reasons = append(reasons, errorInfo.Reason)
+			}
+		}
+		matched := false
+		for _, reason := range reasons {
+			matched = matched || reason == v
+		}
+		if !matched {
+			return fmt.Errorf("the error of the response of %s does not have the google.rpc.ErrorInfo of the reason %v. Actual: %v", name, v, reasons)
+		}
+	}
+	if v, ok := spec[expectedErrorCodeJSONKey]; ok {
+		expectedErrCodes, isArray := v.([]interface{})
+		if !isArray {
+			expectedErrCodes = []interface{}{v}
+		}
+		matched := false
+		for _, code := range expectedErrCodes {
+			matched = matched || codes.Code(uint32(code.(float64))) == status.Code(callErr)
+		}
+		if !matched {
+			if !isArray {
+				return fmt.Errorf("the error code of the response of %s is not as expected. Expected: %d, Actual: %d", name, codes.Code(uint32(v.(float64))), status.Code(callErr))
+			}
+			return fmt.Errorf("the error code of the response of %s is not as expected. Expected one of: %v, Actual: %d", name, v, status.Code(callErr))
+		}
+	} else if callErr == nil {
+		return fmt.Errorf("the response of %s is not an error as expected", name)
+	}
+	if v, ok := spec[forbiddenErrorCodeJSONKey]; ok {
+		forbiddenErrCode := codes.Code(uint32(v.(float64)))
+		if forbiddenErrCode == status.Code(callErr) {
+			return fmt.Errorf("the error code of the response of %s is forbidden. Forbidden: %d, Actual: %d", name, forbiddenErrCode, status.Code(callErr))
+		}
+	}
+	return nil
+}
+
+// checkStatus returns an error if the call did not fail with the status expected by the error object,
+// which has the code, the message_contains and the details. Only the written ones are checked.
+func (runner *TestServiceTestRunner) checkStatus(name string, expected map[string]interface{}, callErr error) error {
+	if callErr == nil {
+		return fmt.Errorf("the response of %s is not an error as expected", name)
+	}
+	st := status.Convert(callErr)
+	if v, ok := expected[errorCodeJSONKey]; ok {
+		code, err := runner.parseCode(v)
+		if err != nil {
+			return err
+		}
+		if code != st.Code() {
+			return fmt.Errorf("the error code of the response of %s is not as expected. Expected: %v, Actual: %v", name, code, st.Code())
+		}
+	}
+	if v, ok := expected[errorMessageContainsJSONKey]; ok {
+		if !strings.Contains(st.Message(), v.(string)) {
+			return fmt.Errorf("the error message %q of the response of %s does not contain %q", st.Message(), name, v)
+		}
+	}
+	if v, ok := expected[errorDetailsJSONKey]; ok {
+		for i, d := range v.([]interface{}) {
+			want, err := runner.parseDetail(d)
+			if err != nil {
+				return fmt.Errorf("the error detail %d is invalid: %v", i, err)
+			}
+			found := false
+			var actualDetails []string
+			for _, detail := range st.Details() {
+				m, ok := detail.(proto.Message)
+				if !ok {
+					actualDetails = append(actualDetails, fmt.Sprint(detail))
+					continue
+				}
+				if proto.Equal(m, want) {
+					found = true
+					break
+				}
+				actualDetails = append(actualDetails, runner.formatDetail(m))
+			}
+			if !found {
+				return fmt.Errorf("the error of the response of %s does not have the detail %d. Expected: %s, Actual: %v", name, i, runner.formatDetail(want), actualDetails)
+			}
+		}
+	}
+	return nil
+}
+
+// parseStatus returns the google.rpc.Status written in its JSON form, whose details are written in the JSON form of google.protobuf.Any.
+func (runner *TestServiceTestRunner) parseStatus(v interface{}) (*spb.Status, error) {
+	statusJSON, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	st := &spb.Status{}
+	if err := protojson.Unmarshal(statusJSON, st); err != nil {
+		return nil, err
+	}
+	return st, nil
+}
+
+// parseCode returns the gRPC code written as a numerical value or as a name such as NotFound.
+func (runner *TestServiceTestRunner) parseCode(v interface{}) (codes.Code, error) {
+	switch code := v.(type) {
+	case float64:
+		return codes.Code(uint32(code)), nil
+	case string:
+		for c := codes.OK; c <= codes.Unauthenticated; c++ {
+			if c.String() == code {
+				return c, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("the code %v is not a gRPC error code", v)
+}
+
+// parseDetail returns the message of the error detail written in the JSON form of google.protobuf.Any,
+// which has the "@type" such as "type.googleapis.com/google.rpc.BadRequest". The google.rpc error details are linked by the generated code,
+// and the other types must be linked in the test binary, for example with the detail_imports option.
+func (runner *TestServiceTestRunner) parseDetail(v interface{}) (proto.Message, error) {
+	object, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("it is not an object")
+	}
+	typeURL, _ := object[anyTypeJSONKey].(string)
+	mt, err := runner.typeResolver().FindMessageByURL(typeURL)
+	if err != nil {
+		return nil, err
+	}
+	fields := map[string]interface{}{}
+	for key, value := range object {
+		if key != anyTypeJSONKey {
+			fields[key] = value
+		}
+	}
+	fieldsJSON, _ := json.Marshal(fields)
+	detail := mt.New().Interface()
+	if err := protojson.Unmarshal(fieldsJSON, detail); err != nil {
+		return nil, err
+	}
+	return detail, nil
+}
+
+// formatDetail returns the full name and the JSON form of the error detail for the failure messages.
+func (runner *TestServiceTestRunner) formatDetail(detail proto.Message) string {
+	detailJSON, _ := protojson.Marshal(detail)
+	return fmt.Sprintf("%s %s", detail.ProtoReflect().Descriptor().FullName(), detailJSON)
+}
+
+// checkResponse returns an error if the response is not as expected by the expected_response of spec, which is a test case or an assertion.
+// The response_format, the assert_fields, the expected_unset_fields and the max_response_bytes of spec are applied.
+func (runner *TestServiceTestRunner) checkResponse(method grpcTestServiceMethod, spec map[string]interface{}, req, res proto.Message, callErr error, compareFunc *func(expectedResponse, response interface{}) error, variables map[string]interface{}) error {
+	responseFormat := responseFormatJSON
+	if v, ok := spec[responseFormatJSONKey]; ok {
+		responseFormat, _ = v.(string)
+	}
+	if responseFormat != responseFormatJSON && responseFormat != responseFormatPrototext {
+		return fmt.Errorf("the %s %v is unknown", responseFormatJSONKey, spec[responseFormatJSONKey])
+	}
+	runOptions, _ := variables[runOptionsVariable].(TestServiceRunOptions)
+	expectation, expected := expectationExact, spec[expectedResponseJSONKey]
+	if responseFormat != responseFormatPrototext {
+		defaultExpectation := expectationExact
+		if runOptions.MatchMode != "" {
+			defaultExpectation = runOptions.MatchMode
+		}
+		expectation, expected = runner.unwrapExpectation(expected, defaultExpectation)
+	}
+	if expectation == expectationAnyOf {
+		alternatives, _ := expected.([]interface{})
+		var failures []string
+		for i, alternative := range alternatives {
+			alternativeSpec := make(map[string]interface{}, len(spec))
+			for key, value := range spec {
+				alternativeSpec[key] = value
+			}
+			alternativeSpec[expectedResponseJSONKey] = alternative
+			err := runner.checkResponse(method, alternativeSpec, req, res, callErr, compareFunc, variables)
+			if err == nil {
+				return nil
+			}
+			failures = append(failures, fmt.Sprintf("%s[%d]: %v", expectationAnyOf, i, err))
+		}
+		return fmt.Errorf("the response of the %s matched none of the %s:\n%s", method.name, expectationAnyOf, strings.Join(failures, "\n"))
+	}
+	if expectation == expectationEchoRequest {
+		if callErr != nil {
+			return fmt.Errorf("the call of the %s failed: %v", method.name, callErr)
+		}
+		return runner.checkEcho(method.name, req, res, expected)
+	}
+	expectedRes := method.newResponse()
+	matchers := map[string]map[string]interface{}{}
+	var subsetPaths []string
+	switch responseFormat {
+	case responseFormatPrototext:
+		resText, _ := expected.(string)
+		if resErr := prototext.Unmarshal([]byte(resText), expectedRes); resErr != nil {
+			return fmt.Errorf("the %s of the %s is not a response in prototext: %v", expectedResponseJSONKey, method.name, resErr)
+		}
+	default:
+		if ref, ok := runner.responseReference(spec); ok {
+			referred, refErr := runner.referredResponse(ref, variables)
+			if refErr != nil {
+				return refErr
+			}
+			expectedRes = proto.Clone(referred)
+			break
+		}
+		if expectation == expectationSchema {
+			break
+		}
+		wrapped, wrapErr := runner.wrapRepeated(expectedRes.ProtoReflect().Descriptor(), expected)
+		if wrapErr != nil {
+			return wrapErr
+		}
+		expected = runner.extractMatchers(wrapped, "", matchers)
+		resJSON, resErr := json.Marshal(expected)
+		if resErr != nil {
+			panic(resErr)
+		}
+		if resErr := runner.unmarshalMessage(resJSON, expectedRes); resErr != nil {
+			return fmt.Errorf("the %s of the %s can not be decoded: %v", expectedResponseJSONKey, method.name, resErr)
+		}
+		if object, ok := expected.(map[string]interface{}); ok && expectation == expectationSubset {
+			var pathsErr error
+			if subsetPaths, pathsErr = runner.subsetPaths(expectedRes.ProtoReflect(), object, ""); pathsErr != nil {
+				return pathsErr
+			}
+		}
+	}
+	if callErr != nil {
+		return fmt.Errorf("the call of the %s failed: %v", method.name, callErr)
+	}
+	if v, ok := spec[maxResponseBytesJSONKey]; ok {
+		if size := proto.Size(res); size > int(v.(float64)) {
+			return fmt.Errorf("the response of the %s was %d bytes, which exceeds the %s of %d", method.name, size, maxResponseBytesJSONKey, int(v.(float64)))
+		}
+	}
+	if expectation == expectationSchema {
+		return runner.checkSchema(method.name, res, expected)
+	}
+	if err := runner.checkMatchers(method.name, res, matchers); err != nil {
+		return err
+	}
+	if v, ok := spec[expectedUnsetFieldsJSONKey]; ok {
+		var paths []string
+		for _, path := range v.([]interface{}) {
+			paths = append(paths, path.(string))
+		}
+		if err := runner.checkUnsetFields(method.name, res, paths); err != nil {
+			return err
+		}
+	}
+	if v, ok := spec[assertFieldsJSONKey]; ok {
+		var paths []string
+		for _, path := range v.([]interface{}) {
+			if _, err := runner.fieldValue(res.ProtoReflect(), path.(string)); err != nil {
+				return err
+			}
+			paths = append(paths, path.(string))
+		}
+		expectedRes = runner.selectFields(expectedRes, paths)
+		res = runner.selectFields(res, paths)
+	}
+	if expectation == expectationSubset {
+		expectedRes = runner.selectFields(expectedRes, subsetPaths)
+		res = runner.selectFields(res, subsetPaths)
+	}
+	matchedPaths := make([]string, 0, len(matchers))
+	for path := range matchers {
+		matchedPaths = append(matchedPaths, path)
+	}
+	res = runner.clearFields(res, matchedPaths)
+	if len(runOptions.IgnoreFields) > 0 {
+		expectedRes, res = runner.clearFields(expectedRes, runOptions.IgnoreFields), runner.clearFields(res, runOptions.IgnoreFields)
+	}
+	return runner.compareResponse(method.name, expectedRes, res, compareFunc)
+}
+
+// echoPaths returns the dot separated paths of the fields of $echoRequest.
+func (runner *TestServiceTestRunner) echoPaths(expected interface{}) ([]string, error) {
+	fields, ok := expected.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("the %s of the %s is not an array", expectationEchoRequest, expectedResponseJSONKey)
+	}
+	paths := make([]string, len(fields))
+	for i, field := range fields {
+		path, ok := field.(string)
+		if !ok {
+			return nil, fmt.Errorf("the field %v of the %s is not a string", field, expectationEchoRequest)
+		}
+		paths[i] = path
+	}
+	return paths, nil
+}
+
+// checkEcho returns an error if a field of the response named by the paths of $echoRequest is not equal to the field of the same path
+// of the request, such as the fields passed through by an echo endpoint.
+func (runner *TestServiceTestRunner) checkEcho(name string, req, res proto.Message, expected interface{}) error {
+	paths, err := runner.echoPaths(expected)
+	if err != nil {
+		return err
+	}
+	for _, path := range paths {
+		reqValue, err := runner.fieldValue(req.ProtoReflect(), path)
+		if err != nil {
+			return fmt.Errorf("the field %s of %s is not in the request", path, expectationEchoRequest)
+		}
+		resValue, err := runner.fieldValue(res.ProtoReflect(), path)
+		if err != nil {
+			return fmt.Errorf("the field %s of %s is not in the response of the %s", path, expectationEchoRequest, name)
+		}
+		if !runner.valuesEqual(reqValue, resValue) {
+			return fmt.Errorf("the field %s of the response of the %s was %v, which is not %v of the request", path, name, resValue, reqValue)
+		}
+	}
+	return nil
+}
+
+// valuesEqual reports whether the values of the fields of different messages are equal, comparing the numbers by their values
+// and the messages, the lists and the maps by their elements.
+func (runner *TestServiceTestRunner) valuesEqual(a, b interface{}) bool {
+	switch a := a.(type) {
+	case protoreflect.Message:
+		b, ok := b.(protoreflect.Message)
+		return ok && proto.Equal(a.Interface(), b.Interface())
+	case protoreflect.List:
+		b, ok := b.(protoreflect.List)
+		if !ok || a.Len() != b.Len() {
+			return false
+		}
+		for i := 0; i < a.Len(); i++ {
+			if !runner.valuesEqual(a.Get(i).Interface(), b.Get(i).Interface()) {
+				return false
+			}
+		}
+		return true
+	case protoreflect.Map:
+		b, ok := b.(protoreflect.Map)
+		if !ok || a.Len() != b.Len() {
+			return false
+		}
+		equal := true
+		a.Range(func(key protoreflect.MapKey, value protoreflect.Value) bool {
+			equal = b.Has(key) && runner.valuesEqual(value.Interface(), b.Get(key).Interface())
+			return equal
+		})
+		return equal
+	case []byte:
+		b, ok := b.([]byte)
+		return ok && bytes.Equal(a, b)
+	}
+	if x, ok := runner.number(a); ok {
+		y, ok := runner.number(b)
+		return ok && x == y
+	}
+	return a == b
+}
+
+// wrapRepeated returns the expected response written as an array as the object of the only repeated field of the message,
+// so that a response wrapping a list can be written as the list. It returns an error if the message does not have exactly one repeated field.
+func (runner *TestServiceTestRunner) wrapRepeated(md protoreflect.MessageDescriptor, expected interface{}) (interface{}, error) {
+	list, ok := expected.([]interface{})
+	if !ok {
+		return expected, nil
+	}
+	var repeated []protoreflect.FieldDescriptor
+	fields := md.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		if fd := fields.Get(i); fd.IsList() {
+			repeated = append(repeated, fd)
+		}
+	}
+	if len(repeated) != 1 {
+		return nil, fmt.Errorf("the %s is an array, but the %s has %d repeated fields instead of exactly one", expectedResponseJSONKey, md.FullName(), len(repeated))
+	}
+	return map[string]interface{}{string(repeated[0].Name()): list}, nil
+}
+
+// unwrapExpectation returns the matcher of the expected response written as {"$subset": {...}} and the expectation in it.
+// The expected response without a matcher is defaultExpectation.
+func (runner *TestServiceTestRunner) unwrapExpectation(expected interface{}, defaultExpectation string) (string, interface{}) {
+	if object, ok := expected.(map[string]interface{}); ok && len(object) == 1 {
+		for key, value := range object {
+			switch key {
+			case expectationExact, expectationSubset, expectationAnyOf, expectationSchema, expectationEchoRequest:
+				return key, value
+			}
+		}
+	}
+	return defaultExpectation, expected
+}
+
+// subsetPaths returns the dot separated paths of the fields written in the expected object of the message for $subset.
+// It descends into the objects of the singular message fields, so that only the fields written in them are compared.
+// It returns an error if a key of the object is not a field of the message, which would select no field to compare.
+func (runner *TestServiceTestRunner) subsetPaths(message protoreflect.Message, expected map[string]interface{}, prefix string) ([]string, error) {
+	var paths []string
+	for key, value := range expected {
+		path := prefix + key
+		fd := runner.fieldByName(message, key)
+		if fd == nil {
+			return nil, fmt.Errorf("the field %s is not in the response", path)
+		}
+		if object, ok := value.(map[string]interface{}); ok && len(object) > 0 && fd.Message() != nil && !fd.IsList() && !fd.IsMap() {
+			nested := message.Get(fd).Message()
+			descends := true
+			for nestedKey := range object {
+				if runner.fieldByName(nested, nestedKey) == nil {
+					// The object is in the JSON form of the message such as google.protobuf.Struct, which is compared as a whole.
+					descends = false
+				}
+			}
+			if descends {
+				nestedPaths, err := runner.subsetPaths(nested, object, path+".")
+				if err != nil {
+					return nil, err
+				}
+				paths = append(paths, nestedPaths...)
+				continue
+			}
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// checkSchema returns an error if the response in the JSON form of protojson with the field names of the proto files
+// does not satisfy the JSON Schema of $schema. The keywords other than those of the schema constants are ignored.
+func (runner *TestServiceTestRunner) checkSchema(name string, response proto.Message, schema interface{}) error {
+	resJSON, err := protojson.MarshalOptions{UseProtoNames: true, EmitUnpopulated: true}.Marshal(response)
+	if err != nil {
+		return err
+	}
+	var value interface{}
+	if err := json.Unmarshal(resJSON, &value); err != nil {
+		return err
+	}
+	if err := runner.validateSchema(schema, value, "$"); err != nil {
+		return fmt.Errorf("the response of the %s does not satisfy the %s: %v", name, expectationSchema, err)
+	}
+	return nil
+}
+
+// validateSchema returns an error if the JSON value at the path does not satisfy the schema.
+func (runner *TestServiceTestRunner) validateSchema(schema interface{}, value interface{}, path string) error {
+	object, ok := schema.(map[string]interface{})
+	if !ok {
+		if allowed, isBool := schema.(bool); isBool && !allowed {
+			return fmt.Errorf("%s is not allowed", path)
+		}
+		return nil
+	}
+	if v, ok := object[schemaType]; ok {
+		types, isArray := v.([]interface{})
+		if !isArray {
+			types = []interface{}{v}
+		}
+		matched := false
+		for _, typeName := range types {
+			matched = matched || runner.isSchemaType(typeName, value)
+		}
+		if !matched {
+			return fmt.Errorf("%s is %v, which is not of the type %v", path, runner.formatVariable(value), v)
+		}
+	}
+	if v, ok := object[schemaEnum].([]interface{}); ok {
+		found := false
+		for _, candidate := range v {
+			found = found || reflect.DeepEqual(candidate, value)
+		}
+		if !found {
+			return fmt.Errorf("%s is %v, which is not one of %v", path, runner.formatVariable(value), v)
+		}
+	}
+	if v, ok := object[schemaConst]; ok && !reflect.DeepEqual(v, value) {
+		return fmt.Errorf("%s is %v, which is not %v", path, runner.formatVariable(value), v)
+	}
+	switch value := value.(type) {
+	case string:
+		if v, ok := object[schemaPattern].(string); ok {
+			re, err := runner.compilePattern(v)
+			if err != nil {
+				return fmt.Errorf("the %s of %s is invalid: %v", schemaPattern, path, err)
+			}
+			if !re.MatchString(value) {
+				return fmt.Errorf("%s is %q, which does not match the %s %s", path, value, schemaPattern, v)
+			}
+		}
+		if v, ok := object[schemaMinLength].(float64); ok && float64(len([]rune(value))) < v {
+			return fmt.Errorf("%s is %q, which is shorter than the %s %v", path, value, schemaMinLength, v)
+		}
+		if v, ok := object[schemaMaxLength].(float64); ok && float64(len([]rune(value))) > v {
+			return fmt.Errorf("%s is %q, which is longer than the %s %v", path, value, schemaMaxLength, v)
+		}
+	case float64:
+		if v, ok := object[schemaMinimum].(float64); ok && value < v {
+			return fmt.Errorf("%s is %v, which is less than the %s %v", path, value, schemaMinimum, v)
+		}
+		if v, ok := object[schemaMaximum].(float64); ok && value > v {
+			return fmt.Errorf("%s is %v, which is greater than the %s %v", path, value, schemaMaximum, v)
+		}
+	case []interface{}:
+		if v, ok := object[schemaMinItems].(float64); ok && float64(len(value)) < v {
+			return fmt.Errorf("%s has %d items, which are fewer than the %s %v", path, len(value), schemaMinItems, v)
+		}
+		if v, ok := object[schemaMaxItems].(float64); ok && float64(len(value)) > v {
+			return fmt.Errorf("%s has %d items, which are more than the %s %v", path, len(value), schemaMaxItems, v)
+		}
+		if items, ok := object[schemaItems]; ok {
+			for i, item := range value {
+				if err := runner.validateSchema(items, item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+		}
+	case map[string]interface{}:
+		required, _ := object[schemaRequired].([]interface{})
+		for _, key := range required {
+			if _, ok := value[fmt.Sprint(key)]; !ok {
+				return fmt.Errorf("%s does not have the required %v", path, key)
+			}
+		}
+		properties, _ := object[schemaProperties].(map[string]interface{})
+		for _, key := range runner.sortedKeys(value) {
+			propertySchema, ok := properties[key]
+			if !ok {
+				propertySchema, ok = object[schemaAdditionalProperties]
+			}
+			if !ok {
+				continue
+			}
+			if err := runner.validateSchema(propertySchema, value[key], path+"."+key); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// isSchemaType reports whether the JSON value is of the type of the JSON Schema such as integer.
+func (runner *TestServiceTestRunner) isSchemaType(typeName interface{}, value interface{}) bool {
+	switch value := value.(type) {
+	case nil:
+		return typeName == "null"
+	case bool:
+		return typeName == "boolean"
+	case string:
+		return typeName == "string"
+	case float64:
+		return typeName == "number" || typeName == "integer" && value == float64(int64(value))
+	case []interface{}:
+		return typeName == "array"
+	case map[string]interface{}:
+		return typeName == "object"
+	}
+	return false
+}
+
+// checkAssertions evaluates each of the assertions independently, and returns an error listing all of the failed assertions.
+func (runner *TestServiceTestRunner) checkAssertions(method grpcTestServiceMethod, assertions []interface{}, req, res proto.Message, header, trailer metadata.MD, callErr error, elapsed, timeout time.Duration, compareFunc *func(expectedResponse, response interface{}) error, variables map[string]interface{}) error {
+	var failures []string
+	for i, v := range assertions {
+		assertion := v.(map[string]interface{})
+		var err error
+		switch assertion[assertionTypeJSONKey] {
+		case assertionTypeResponse:
+			err = runner.checkResponse(method, assertion, req, res, callErr, compareFunc, variables)
+		case assertionTypeError:
+			err = runner.checkError(method.name, assertion, callErr)
+		case assertionTypeHeader:
+			for key, value := range assertion[expectedHeaderJSONKey].(map[string]interface{}) {
+				if values := header.Get(key); len(values) == 0 || values[0] != value.(string) {
+					err = fmt.Errorf("the header %s of the response of %s was %v, which is not %q", key, method.name, values, value)
+					break
+				}
+			}
+		case assertionTypeTrailer:
+			for key, value := range assertion[expectedTrailerJSONKey].(map[string]interface{}) {
+				if err = runner.checkTrailer(method.name, trailer, key, value); err != nil {
+					break
+				}
+			}
+		case assertionTypeLatency:
+			maxLatency := time.Duration(assertion[maxLatencyMsJSONKey].(float64)) * time.Millisecond
+			if elapsed > maxLatency {
+				err = fmt.Errorf("the call of %s took %v, which is longer than %v", method.name, elapsed, maxLatency)
+			}
+		case assertionTypeDeadline:
+			err = runner.checkDeadline(method.name, assertion, res, callErr, timeout)
+		default:
+			err = fmt.Errorf("the type %v is unknown", assertion[assertionTypeJSONKey])
+		}
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("the assertion %d failed: %v", i, err))
+		}
+	}
+	if len(failures) > 0 {
+		return errors.New(strings.Join(failures, "\n"))
+	}
+	return nil
+}
+
+// checkTrailer returns an error if the trailer of the response does not have the expected value for the key.
+// A number is compared with the value parsed as an integer, such as the number of the retries the server performed.
+func (runner *TestServiceTestRunner) checkTrailer(name string, trailer metadata.MD, key string, expected interface{}) error {
+	values := trailer.Get(key)
+	if len(values) == 0 {
+		return fmt.Errorf("the trailer %s of the response of %s was not set", key, name)
+	}
+	number, ok := expected.(float64)
+	if !ok {
+		if values[0] != expected.(string) {
+			return fmt.Errorf("the trailer %s of the response of %s was %q, which is not %q", key, name, values[0], expected)
+		}
+		return nil
+	}
+	actual, err := strconv.ParseInt(values[0], 10, 64)
+	if err != nil {
+		return fmt.Errorf("the trailer %s of the response of %s was %q, which is not an integer", key, name, values[0])
+	}
+	if float64(actual) != number {
+		return fmt.Errorf("the trailer %s of the response of %s was %d, which is not %v", key, name, actual, number)
+	}
+	return nil
+}
+
+// checkDeadline returns an error if the field of the response, in which the server echoes the remaining time until the deadline it observed,
+// is not within the tolerance of the timeout of the test case. The field is a google.protobuf.Duration or an integer in milliseconds.
+func (runner *TestServiceTestRunner) checkDeadline(name string, spec map[string]interface{}, res proto.Message, callErr error, timeout time.Duration) error {
+	if callErr != nil {
+		return fmt.Errorf("the call of %s failed: %v", name, callErr)
+	}
+	path := spec[fieldJSONKey].(string)
+	tolerance := defaultDeadlineTolerance
+	if v, ok := spec[toleranceMsJSONKey]; ok {
+		tolerance = time.Duration(v.(float64)) * time.Millisecond
+	}
+	value, err := runner.fieldValue(res.ProtoReflect(), path)
+	if err != nil {
+		return err
+	}
+	var remaining time.Duration
+	if message, ok := value.(protoreflect.Message); ok && message.Descriptor().FullName() == "google.protobuf.Duration" {
+		fields := message.Descriptor().Fields()
+		remaining = time.Duration(message.Get(fields.ByName("seconds")).Int())*time.Second + time.Duration(message.Get(fields.ByName("nanos")).Int())
+	} else if ms, ok := runner.number(value); ok {
+		remaining = time.Duration(ms) * time.Millisecond
+	} else {
+		return fmt.Errorf("the field %s of the response of %s is neither a duration nor a number", path, name)
+	}
+	if remaining > timeout || remaining < timeout-tolerance {
+		return fmt.Errorf("the remaining time %v of the deadline in the field %s of the response of %s is not within %v of the timeout %v", remaining, path, name, tolerance, timeout)
+	}
+	return nil
+}
+
+// compareResponse compares the expected response and the actual response of the gRPC method by compareFunc,
+// or by proto.Equal if compareFunc is nil, which compares the map fields regardless of their order
+// and ignores the internal state of the messages unlike reflect.DeepEqual.
+func (runner *TestServiceTestRunner) compareResponse(name string, expectedRes, res proto.Message, compareFunc *func(expectedResponse, response interface{}) error) error {
+	if compareFunc != nil {
+		compare := *compareFunc
+		return compare(reflect.ValueOf(expectedRes).Elem().Interface(), reflect.ValueOf(res).Elem().Interface())
+	}
+	if len(runner.FieldComparers) > 0 {
+		expectedRes, res = proto.Clone(expectedRes), proto.Clone(res)
+		if err := runner.applyFieldComparers(expectedRes.ProtoReflect(), res.ProtoReflect(), ""); err != nil {
+			return fmt.Errorf("the actual response of the %s was not equal to the expected response: %v", name, err)
+		}
+	}
+	if !proto.Equal(expectedRes, res) {
+		return fmt.Errorf("the actual response of the %s was not equal to the expected response", name)
+	}
+	return nil
+}
+
+// applyFieldComparers compares the messages of the types of FieldComparers in the expected message and the actual message,
+// and clears them so that the rest of the messages can be compared by proto.Equal. path is the path of the messages for the errors.
+func (runner *TestServiceTestRunner) applyFieldComparers(expected, actual protoreflect.Message, path string) error {
+	if comparer, ok := runner.FieldComparers[string(expected.Descriptor().FullName())]; ok {
+		if err := comparer(expected.Interface(), actual.Interface()); err != nil {
+			if path == "" {
+				return err
+			}
+			return fmt.Errorf("the field %s: %v", path, err)
+		}
+		fields := expected.Descriptor().Fields()
+		for i := 0; i < fields.Len(); i++ {
+			expected.Clear(fields.Get(i))
+			actual.Clear(fields.Get(i))
+		}
+		return nil
+	}
+	fields := expected.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		fieldPath := string(fd.Name())
+		if path != "" {
+			fieldPath = path + "." + fieldPath
+		}
+		switch {
+		case fd.IsList() && fd.Message() != nil:
+			expectedList, actualList := expected.Get(fd).List(), actual.Get(fd).List()
+			for j := 0; j < expectedList.Len() && j < actualList.Len(); j++ {
+				if err := runner.applyFieldComparers(expectedList.Get(j).Message(), actualList.Get(j).Message(), fmt.Sprintf("%s[%d]", fieldPath, j)); err != nil {
+					return err
+				}
+			}
+		case fd.IsMap() && fd.MapValue().Message() != nil:
+			expectedMap, actualMap := expected.Get(fd).Map(), actual.Get(fd).Map()
+			var err error
+			expectedMap.Range(func(key protoreflect.MapKey, value protoreflect.Value) bool {
+				if actualMap.Has(key) {
+					err = runner.applyFieldComparers(value.Message(), actualMap.Get(key).Message(), fmt.Sprintf("%s[%v]", fieldPath, key.Interface()))
+				}
+				return err == nil
+			})
+			if err != nil {
+				return err
+			}
+		case fd.Message() != nil && !fd.IsMap():
+			if expected.Has(fd) && actual.Has(fd) {
+				if err := runner.applyFieldComparers(expected.Get(fd).Message(), actual.Get(fd).Message(), fieldPath); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// TestServiceTypeResolver resolves the types of google.protobuf.Any, such as protoregistry.GlobalTypes.
+type TestServiceTypeResolver interface {
+	protoregistry.MessageTypeResolver
+	protoregistry.ExtensionTypeResolver
+}
+
+// typeResolver returns TypeResolver, or protoregistry.GlobalTypes if it is nil.
+func (runner *TestServiceTestRunner) typeResolver() TestServiceTypeResolver {
+	if runner.TypeResolver == nil {
+		return protoregistry.GlobalTypes
+	}
+	return runner.TypeResolver
+}
+
+// unmarshalMessage unmarshals the JSON written in the scenario into the message. The messages having a field of google.protobuf.Any
+// or google.protobuf.Struct at any depth, which encoding/json can not decode, are unmarshaled by protojson with the types of TypeResolver.
+// The others are unmarshaled by encoding/json, which ignores the unknown fields unlike protojson.
+func (runner *TestServiceTestRunner) unmarshalMessage(data []byte, message proto.Message) error {
+	if !runner.hasDynamicFields(message.ProtoReflect().Descriptor(), map[protoreflect.FullName]bool{}) {
+		return json.Unmarshal(data, message)
+	}
+	return protojson.UnmarshalOptions{Resolver: runner.typeResolver()}.Unmarshal(data, message)
+}
+
+// marshalMessage marshals the message into the JSON written in the scenario, which unmarshalMessage unmarshals into the message.
+func (runner *TestServiceTestRunner) marshalMessage(message proto.Message) ([]byte, error) {
+	if !runner.hasDynamicFields(message.ProtoReflect().Descriptor(), map[protoreflect.FullName]bool{}) {
+		return json.Marshal(message)
+	}
+	return protojson.MarshalOptions{UseProtoNames: true, Resolver: runner.typeResolver()}.Marshal(message)
+}
+
+// hasDynamicFields reports whether the message is or has a field of google.protobuf.Any, Struct, Value or ListValue at any depth.
+// visited has the messages already checked, so that the recursive messages are checked once.
+func (runner *TestServiceTestRunner) hasDynamicFields(md protoreflect.MessageDescriptor, visited map[protoreflect.FullName]bool) bool {
+	switch md.FullName() {
+	case "google.protobuf.Any", "google.protobuf.Struct", "google.protobuf.Value", "google.protobuf.ListValue":
+		return true
+	}
+	if visited[md.FullName()] {
+		return false
+	}
+	visited[md.FullName()] = true
+	fields := md.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		if fd.IsMap() {
+			fd = fd.MapValue()
+		}
+		if fd.Message() != nil && runner.hasDynamicFields(fd.Message(), visited) {
+			return true
+		}
+	}
+	return false
+}
+
+// buildRequest unmarshals the request written in the scenario into req through JSON.
+func (runner *TestServiceTestRunner) buildRequest(request interface{}, req proto.Message) error {
+	reqJSON, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+	return runner.unmarshalMessage(reqJSON, req)
+}
+
+func (runner *TestServiceTestRunner) testHello(ctx context.Context, t TestServiceReporter, testCase map[string]interface{}, compareFunc *func(expectedResponse, response interface{}) error, variables map[string]interface{}) {
+	runner.testMethod(ctx, t, testCase, compareFunc, variables, runner.methodHello())
+}
+
+func (runner *TestServiceTestRunner) methodHello() grpcTestServiceMethod {
+	return grpcTestServiceMethod{
+		name: "Hello",
+		newRequest: func() proto.Message {
+			return &HReq{}
+		},
+		newResponse: func() proto.Message {
+			return &HRes{}
+		},
+		invoke: func(ctx context.Context, req proto.Message, opts ...grpc.CallOption) (proto.Message, error) {
+			return runner.Client.Hello(ctx, req.(*HReq), opts...)
+		},
+	}
+}
+
+// BuildHelloRequest builds the request of Hello from the request written as in the scenario in the same way as RunGRPCTest,
+// so that the requests of the tests written in Go are consistent with the scenario.
+func (runner *TestServiceTestRunner) BuildHelloRequest(request map[string]interface{}) (*HReq, error) {
+	req := &HReq{}
+	if err := runner.buildRequest(request, req); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// PollUntilHello calls Hello with the request every interval until predicate holds for the response and the error of the call,
+// for example to wait for an eventually consistent read, and returns the response. It fails t if predicate does not hold within the timeout.
+func (runner *TestServiceTestRunner) PollUntilHello(ctx context.Context, t *testing.T, req *HReq, predicate func(res *HRes, err error) bool, timeout, interval time.Duration) *HRes {
+	t.Helper()
+	invoke := func(ctx context.Context) (proto.Message, error) {
+		return runner.Client.Hello(ctx, req)
+	}
+	done := func(res proto.Message, err error) bool {
+		typed, _ := res.(*HRes)
+		return predicate(typed, err)
+	}
+	res, err := runner.poll(ctx, "Hello", invoke, done, timeout, interval)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	typed, _ := res.(*HRes)
+	return typed
+}
+
+// CompareHello compares the expected response and the actual response of Hello in the same way as RunGRPCTest,
+// so that compareFunc can be tested without calling the server.
+func (runner *TestServiceTestRunner) CompareHello(expectedResponse, response *HRes, compareFunc *func(expectedResponse, response interface{}) error) error {
+	return runner.compareResponse("Hello", expectedResponse, response, compareFunc)
+}
+
+func (runner *TestServiceTestRunner) testBye(ctx context.Context, t TestServiceReporter, testCase map[string]interface{}, compareFunc *func(expectedResponse, response interface{}) error, variables map[string]interface{}) {
+	runner.testMethod(ctx, t, testCase, compareFunc, variables, runner.methodBye())
+}
+
+func (runner *TestServiceTestRunner) methodBye() grpcTestServiceMethod {
+	return grpcTestServiceMethod{
+		name: "Bye",
+		newRequest: func() proto.Message {
+			return &BReq{}
+		},
+		newResponse: func() proto.Message {
+			return &BRes{}
+		},
+		invoke: func(ctx context.Context, req proto.Message, opts ...grpc.CallOption) (proto.Message, error) {
+			return runner.Client.Bye(ctx, req.(*BReq), opts...)
+		},
+	}
+}
+
+// BuildByeRequest builds the request of Bye from the request written as in the scenario in the same way as RunGRPCTest,
+// so that the requests of the tests written in Go are consistent with the scenario.
+func (runner *TestServiceTestRunner) BuildByeRequest(request map[string]interface{}) (*BReq, error) {
+	req := &BReq{}
+	if err := runner.buildRequest(request, req); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// PollUntilBye calls Bye with the request every interval until predicate holds for the response and the error of the call,
+// for example to wait for an eventually consistent read, and returns the response. It fails t if predicate does not hold within the timeout.
+func (runner *TestServiceTestRunner) PollUntilBye(ctx context.Context, t *testing.T, req *BReq, predicate func(res *BRes, err error) bool, timeout, interval time.Duration) *BRes {
+	t.Helper()
+	invoke := func(ctx context.Context) (proto.Message, error) {
+		return runner.Client.Bye(ctx, req)
+	}
+	done := func(res proto.Message, err error) bool {
+		typed, _ := res.(*BRes)
+		return predicate(typed, err)
+	}
+	res, err := runner.poll(ctx, "Bye", invoke, done, timeout, interval)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	typed, _ := res.(*BRes)
+	return typed
+}
+
+// CompareBye compares the expected response and the actual response of Bye in the same way as RunGRPCTest,
+// so that compareFunc can be tested without calling the server.
+func (runner *TestServiceTestRunner) CompareBye(expectedResponse, response *BRes, compareFunc *func(expectedResponse, response interface{}) error) error {
+	return runner.compareResponse("Bye", expectedResponse, response, compareFunc)
+}
+`
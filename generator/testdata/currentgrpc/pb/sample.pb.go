@@ -0,0 +1,487 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.12
+// 	protoc        (unknown)
+// source: sample.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type HelloRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ReqMsg        string                 `protobuf:"bytes,1,opt,name=req_msg,json=reqMsg,proto3" json:"req_msg,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HelloRequest) Reset() {
+	*x = HelloRequest{}
+	mi := &file_sample_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HelloRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HelloRequest) ProtoMessage() {}
+
+func (x *HelloRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_sample_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HelloRequest.ProtoReflect.Descriptor instead.
+func (*HelloRequest) Descriptor() ([]byte, []int) {
+	return file_sample_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *HelloRequest) GetReqMsg() string {
+	if x != nil {
+		return x.ReqMsg
+	}
+	return ""
+}
+
+type HelloResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ResMsg        string                 `protobuf:"bytes,1,opt,name=res_msg,json=resMsg,proto3" json:"res_msg,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HelloResponse) Reset() {
+	*x = HelloResponse{}
+	mi := &file_sample_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HelloResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HelloResponse) ProtoMessage() {}
+
+func (x *HelloResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_sample_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HelloResponse.ProtoReflect.Descriptor instead.
+func (*HelloResponse) Descriptor() ([]byte, []int) {
+	return file_sample_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *HelloResponse) GetResMsg() string {
+	if x != nil {
+		return x.ResMsg
+	}
+	return ""
+}
+
+type ByeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ReqMsg        string                 `protobuf:"bytes,1,opt,name=req_msg,json=reqMsg,proto3" json:"req_msg,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ByeRequest) Reset() {
+	*x = ByeRequest{}
+	mi := &file_sample_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ByeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ByeRequest) ProtoMessage() {}
+
+func (x *ByeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_sample_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ByeRequest.ProtoReflect.Descriptor instead.
+func (*ByeRequest) Descriptor() ([]byte, []int) {
+	return file_sample_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ByeRequest) GetReqMsg() string {
+	if x != nil {
+		return x.ReqMsg
+	}
+	return ""
+}
+
+type ByeResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ResMsg        string                 `protobuf:"bytes,1,opt,name=res_msg,json=resMsg,proto3" json:"res_msg,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ByeResponse) Reset() {
+	*x = ByeResponse{}
+	mi := &file_sample_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ByeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ByeResponse) ProtoMessage() {}
+
+func (x *ByeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_sample_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ByeResponse.ProtoReflect.Descriptor instead.
+func (*ByeResponse) Descriptor() ([]byte, []int) {
+	return file_sample_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ByeResponse) GetResMsg() string {
+	if x != nil {
+		return x.ResMsg
+	}
+	return ""
+}
+
+type GetUserRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetUserRequest) Reset() {
+	*x = GetUserRequest{}
+	mi := &file_sample_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetUserRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetUserRequest) ProtoMessage() {}
+
+func (x *GetUserRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_sample_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetUserRequest.ProtoReflect.Descriptor instead.
+func (*GetUserRequest) Descriptor() ([]byte, []int) {
+	return file_sample_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetUserRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type User struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Password      string                 `protobuf:"bytes,3,opt,name=password,proto3" json:"password,omitempty"`
+	LoginCount    int32                  `protobuf:"varint,4,opt,name=login_count,json=loginCount,proto3" json:"login_count,omitempty"`
+	Tags          []string               `protobuf:"bytes,5,rep,name=tags,proto3" json:"tags,omitempty"`
+	Attributes    map[string]string      `protobuf:"bytes,6,rep,name=attributes,proto3" json:"attributes,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Profile       *Profile               `protobuf:"bytes,7,opt,name=profile,proto3" json:"profile,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *User) Reset() {
+	*x = User{}
+	mi := &file_sample_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *User) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*User) ProtoMessage() {}
+
+func (x *User) ProtoReflect() protoreflect.Message {
+	mi := &file_sample_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use User.ProtoReflect.Descriptor instead.
+func (*User) Descriptor() ([]byte, []int) {
+	return file_sample_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *User) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *User) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *User) GetPassword() string {
+	if x != nil {
+		return x.Password
+	}
+	return ""
+}
+
+func (x *User) GetLoginCount() int32 {
+	if x != nil {
+		return x.LoginCount
+	}
+	return 0
+}
+
+func (x *User) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *User) GetAttributes() map[string]string {
+	if x != nil {
+		return x.Attributes
+	}
+	return nil
+}
+
+func (x *User) GetProfile() *Profile {
+	if x != nil {
+		return x.Profile
+	}
+	return nil
+}
+
+type Profile struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Bio           string                 `protobuf:"bytes,1,opt,name=bio,proto3" json:"bio,omitempty"`
+	Country       string                 `protobuf:"bytes,2,opt,name=country,proto3" json:"country,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Profile) Reset() {
+	*x = Profile{}
+	mi := &file_sample_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Profile) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Profile) ProtoMessage() {}
+
+func (x *Profile) ProtoReflect() protoreflect.Message {
+	mi := &file_sample_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Profile.ProtoReflect.Descriptor instead.
+func (*Profile) Descriptor() ([]byte, []int) {
+	return file_sample_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Profile) GetBio() string {
+	if x != nil {
+		return x.Bio
+	}
+	return ""
+}
+
+func (x *Profile) GetCountry() string {
+	if x != nil {
+		return x.Country
+	}
+	return ""
+}
+
+var File_sample_proto protoreflect.FileDescriptor
+
+const file_sample_proto_rawDesc = "" +
+	"\n" +
+	"\fsample.proto\"'\n" +
+	"\fHelloRequest\x12\x17\n" +
+	"\areq_msg\x18\x01 \x01(\tR\x06reqMsg\"(\n" +
+	"\rHelloResponse\x12\x17\n" +
+	"\ares_msg\x18\x01 \x01(\tR\x06resMsg\"%\n" +
+	"\n" +
+	"ByeRequest\x12\x17\n" +
+	"\areq_msg\x18\x01 \x01(\tR\x06reqMsg\"&\n" +
+	"\vByeResponse\x12\x17\n" +
+	"\ares_msg\x18\x01 \x01(\tR\x06resMsg\" \n" +
+	"\x0eGetUserRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\x95\x02\n" +
+	"\x04User\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x1a\n" +
+	"\bpassword\x18\x03 \x01(\tR\bpassword\x12\x1f\n" +
+	"\vlogin_count\x18\x04 \x01(\x05R\n" +
+	"loginCount\x12\x12\n" +
+	"\x04tags\x18\x05 \x03(\tR\x04tags\x125\n" +
+	"\n" +
+	"attributes\x18\x06 \x03(\v2\x15.User.AttributesEntryR\n" +
+	"attributes\x12\"\n" +
+	"\aprofile\x18\a \x01(\v2\b.ProfileR\aprofile\x1a=\n" +
+	"\x0fAttributesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"5\n" +
+	"\aProfile\x12\x10\n" +
+	"\x03bio\x18\x01 \x01(\tR\x03bio\x12\x18\n" +
+	"\acountry\x18\x02 \x01(\tR\acountry2{\n" +
+	"\x06Sample\x12(\n" +
+	"\x05Hello\x12\r.HelloRequest\x1a\x0e.HelloResponse\"\x00\x12\"\n" +
+	"\x03Bye\x12\v.ByeRequest\x1a\f.ByeResponse\"\x00\x12#\n" +
+	"\aGetUser\x12\x0f.GetUserRequest\x1a\x05.User\"\x00B\x04Z\x02pbb\x06proto3"
+
+var (
+	file_sample_proto_rawDescOnce sync.Once
+	file_sample_proto_rawDescData []byte
+)
+
+func file_sample_proto_rawDescGZIP() []byte {
+	file_sample_proto_rawDescOnce.Do(func() {
+		file_sample_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_sample_proto_rawDesc), len(file_sample_proto_rawDesc)))
+	})
+	return file_sample_proto_rawDescData
+}
+
+var file_sample_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_sample_proto_goTypes = []any{
+	(*HelloRequest)(nil),   // 0: HelloRequest
+	(*HelloResponse)(nil),  // 1: HelloResponse
+	(*ByeRequest)(nil),     // 2: ByeRequest
+	(*ByeResponse)(nil),    // 3: ByeResponse
+	(*GetUserRequest)(nil), // 4: GetUserRequest
+	(*User)(nil),           // 5: User
+	(*Profile)(nil),        // 6: Profile
+	nil,                    // 7: User.AttributesEntry
+}
+var file_sample_proto_depIdxs = []int32{
+	7, // 0: User.attributes:type_name -> User.AttributesEntry
+	6, // 1: User.profile:type_name -> Profile
+	0, // 2: Sample.Hello:input_type -> HelloRequest
+	2, // 3: Sample.Bye:input_type -> ByeRequest
+	4, // 4: Sample.GetUser:input_type -> GetUserRequest
+	1, // 5: Sample.Hello:output_type -> HelloResponse
+	3, // 6: Sample.Bye:output_type -> ByeResponse
+	5, // 7: Sample.GetUser:output_type -> User
+	5, // [5:8] is the sub-list for method output_type
+	2, // [2:5] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_sample_proto_init() }
+func file_sample_proto_init() {
+	if File_sample_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_sample_proto_rawDesc), len(file_sample_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   8,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_sample_proto_goTypes,
+		DependencyIndexes: file_sample_proto_depIdxs,
+		MessageInfos:      file_sample_proto_msgTypes,
+	}.Build()
+	File_sample_proto = out.File
+	file_sample_proto_goTypes = nil
+	file_sample_proto_depIdxs = nil
+}
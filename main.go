@@ -1,42 +1,140 @@
-package main
-
-import (
-	"os"
-
-	"github.com/golang/protobuf/protoc-gen-go/descriptor"
-
-	"github.com/yoshd/protoc-gen-stest/generator"
-	"github.com/yoshd/protoc-gen-stest/processor"
-)
-
-var generateCodeFunc = func(packageName, serviceName string, methods []*descriptor.MethodDescriptorProto) string {
-	grpcMethods := make([]generator.GRPCMethod, len(methods))
-	for i, m := range methods {
-		reqType := m.GetInputType()[1:]
-		resType := m.GetOutputType()[1:]
-		grpcMethods[i] = generator.GRPCMethod{
-			Name:         m.GetName(),
-			RequestType:  reqType,
-			ResponseType: resType,
-		}
-	}
-	grpcCodeGenInfo := generator.GRPCCodeGenInfo{
-		Package:         packageName,
-		GRPCServiceName: serviceName,
-		GRPCMethods:     grpcMethods,
-	}
-	code, err := generator.GenerateGRPCTestCode(grpcCodeGenInfo)
-	if err != nil {
-		panic(err)
-	}
-	return code
-}
-
-func main() {
-	req, err := processor.ParseRequest(os.Stdin)
-	if err != nil {
-		panic(err)
-	}
-	res := processor.ProcessRequest(req, generateCodeFunc)
-	processor.EmitResponse(res)
-}
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/golang/protobuf/protoc-gen-go/descriptor"
+	plugin "github.com/golang/protobuf/protoc-gen-go/plugin"
+
+	"github.com/yoshd/protoc-gen-stest/generator"
+	"github.com/yoshd/protoc-gen-stest/processor"
+)
+
+type generateCodeFunc func(packageName, serviceName string, methods []*descriptor.MethodDescriptorProto) string
+
+type generateMethodCodeFunc func(packageName, serviceName string, methods []*descriptor.MethodDescriptorProto, methodName string) string
+
+// newGenerateCodeFuncs returns the function to generate the code with the options given as the parameter of protoc,
+// the function to generate the code of the CLI, which is nil unless the cli_import_path option is given,
+// the function to generate the code of the Ginkgo specs, which is nil unless the ginkgo option is true,
+// and the function to generate the code of each method, which is nil unless the split_methods option is true.
+// The types of the methods are checked to be in messageTypes, and the methods are documented by methodComments.
+func newGenerateCodeFuncs(params map[string]string, messageTypes []string, methodComments map[string]string) (generateCodeFunc, generateCodeFunc, generateCodeFunc, generateMethodCodeFunc, error) {
+	options := generator.GRPCCodeGenInfo{MessageTypes: messageTypes}
+	ginkgo := false
+	for key, value := range params {
+		switch key {
+		case "dispatch":
+			options.Dispatch = value
+		case "build_tag":
+			options.BuildTag = value
+		case "cli_import_path":
+			options.ImportPath = value
+		case "detail_imports":
+			options.DetailImports = strings.Split(value, "+")
+		case "split_methods":
+			splitMethods, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, nil, nil, nil, fmt.Errorf("the parameter split_methods must be a boolean: %v", err)
+			}
+			options.SplitMethods = splitMethods
+		case "ginkgo":
+			var err error
+			if ginkgo, err = strconv.ParseBool(value); err != nil {
+				return nil, nil, nil, nil, fmt.Errorf("the parameter ginkgo must be a boolean: %v", err)
+			}
+		default:
+			return nil, nil, nil, nil, fmt.Errorf("unknown parameter %s", key)
+		}
+	}
+	newCodeGenInfo := func(packageName, serviceName string, methods []*descriptor.MethodDescriptorProto) generator.GRPCCodeGenInfo {
+		grpcMethods := make([]generator.GRPCMethod, len(methods))
+		for i, m := range methods {
+			reqType := m.GetInputType()[1:]
+			resType := m.GetOutputType()[1:]
+			grpcMethods[i] = generator.GRPCMethod{
+				Name:         m.GetName(),
+				RequestType:  reqType,
+				ResponseType: resType,
+				Comment:      methodComments[serviceName+"."+m.GetName()],
+			}
+		}
+		grpcCodeGenInfo := options
+		grpcCodeGenInfo.Package = packageName
+		grpcCodeGenInfo.GRPCServiceName = serviceName
+		grpcCodeGenInfo.GRPCMethods = grpcMethods
+		return grpcCodeGenInfo
+	}
+	newGenerateFunc := func(generate func(generator.GRPCCodeGenInfo) (string, error)) generateCodeFunc {
+		return func(packageName, serviceName string, methods []*descriptor.MethodDescriptorProto) string {
+			code, err := generate(newCodeGenInfo(packageName, serviceName, methods))
+			if err != nil {
+				panic(err)
+			}
+			return code
+		}
+	}
+	var generateCLICode generateCodeFunc
+	if options.ImportPath != "" {
+		generateCLICode = newGenerateFunc(generator.GenerateCLICode)
+	}
+	var generateGinkgoCode generateCodeFunc
+	if ginkgo {
+		generateGinkgoCode = newGenerateFunc(generator.GenerateGinkgoCode)
+	}
+	var generateMethodCode generateMethodCodeFunc
+	if options.SplitMethods {
+		generateMethodCode = func(packageName, serviceName string, methods []*descriptor.MethodDescriptorProto, methodName string) string {
+			code, err := generator.GenerateGRPCTestMethodCode(newCodeGenInfo(packageName, serviceName, methods), methodName)
+			if err != nil {
+				panic(err)
+			}
+			return code
+		}
+	}
+	return newGenerateFunc(generator.GenerateGRPCTestCode), generateCLICode, generateGinkgoCode, generateMethodCode, nil
+}
+
+// main runs as a protoc plugin, or generates the code from the FileDescriptorSet given by the descriptor_set_in flag
+// into the directory given by the out flag with the options given by the param flag as the parameter of protoc.
+func main() {
+	descriptorSetIn := flag.String("descriptor_set_in", "", "the file serialized by protoc --descriptor_set_out --include_imports")
+	param := flag.String("param", "", "the options in the form of key1=value1,key2=value2")
+	out := flag.String("out", ".", "the directory of the generated code")
+	flag.Parse()
+	var req *plugin.CodeGeneratorRequest
+	var err error
+	if *descriptorSetIn == "" {
+		req, err = processor.ParseRequest(os.Stdin)
+	} else {
+		var f *os.File
+		if f, err = os.Open(*descriptorSetIn); err != nil {
+			panic(err)
+		}
+		req, err = processor.ParseDescriptorSet(f, *param)
+		f.Close()
+	}
+	if err != nil {
+		panic(err)
+	}
+	params, err := processor.ParseParameter(req.GetParameter())
+	if err != nil {
+		panic(err)
+	}
+	generateCode, generateCLICode, generateGinkgoCode, generateMethodCode, err := newGenerateCodeFuncs(params, processor.MessageTypes(req), processor.MethodComments(req))
+	if err != nil {
+		panic(err)
+	}
+	res := processor.ProcessRequest(req, generateCode, generateCLICode, generateGinkgoCode, generateMethodCode)
+	if *descriptorSetIn != "" {
+		if err := processor.WriteResponse(res, *out); err != nil {
+			panic(err)
+		}
+		return
+	}
+	processor.EmitResponse(res)
+}
@@ -1,77 +1,206 @@
-package processor
-
-import (
-	"io"
-	"io/ioutil"
-	"os"
-	"strings"
-	"unicode"
-
-	"github.com/golang/protobuf/proto"
-	descriptor "github.com/golang/protobuf/protoc-gen-go/descriptor"
-	plugin "github.com/golang/protobuf/protoc-gen-go/plugin"
-)
-
-// ParseRequest parses the input and returns the CodeGeneratorRequest of protoc.
-func ParseRequest(r io.Reader) (*plugin.CodeGeneratorRequest, error) {
-	buf, err := ioutil.ReadAll(r)
-	if err != nil {
-		return nil, err
-	}
-	var req plugin.CodeGeneratorRequest
-	if err = proto.Unmarshal(buf, &req); err != nil {
-		return nil, err
-	}
-	return &req, nil
-}
-
-// ProcessRequest processes the request and returns a response to generate the code.
-func ProcessRequest(req *plugin.CodeGeneratorRequest, genCodeFunc func(packageName, serviceName string, methods []*descriptor.MethodDescriptorProto) string) *plugin.CodeGeneratorResponse {
-	files := make(map[string]*descriptor.FileDescriptorProto)
-	for _, f := range req.ProtoFile {
-		files[f.GetName()] = f
-	}
-	var res plugin.CodeGeneratorResponse
-	for _, fname := range req.FileToGenerate {
-		f := files[fname]
-		for _, service := range f.GetService() {
-			packageName := f.GetOptions().GetGoPackage()
-			serviceName := service.GetName()
-			methods := service.GetMethod()
-			genCode := genCodeFunc(packageName, serviceName, methods)
-			serviceNameSnakeCase := toSnakeCase(service.GetName())
-			outputFname := serviceNameSnakeCase + "_scenariotest.go"
-			res.File = append(res.File, &plugin.CodeGeneratorResponse_File{
-				Name:    proto.String(outputFname),
-				Content: proto.String(genCode),
-			})
-		}
-	}
-	return &res
-}
-
-// EmitResponse returns the response of protoc
-func EmitResponse(res *plugin.CodeGeneratorResponse) error {
-	buf, err := proto.Marshal(res)
-	if err != nil {
-		return err
-	}
-	_, err = os.Stdout.Write(buf)
-	return err
-}
-
-func toSnakeCase(str string) (snakeCaseStr string) {
-	for i, c := range str {
-		if unicode.IsUpper(c) {
-			lower := strings.ToLower(string(c))
-			if i == 0 {
-				snakeCaseStr = lower
-				continue
-			}
-			snakeCaseStr = snakeCaseStr + "_" + lower
-			continue
-		}
-		snakeCaseStr = snakeCaseStr + string(c)
-	}
-	return snakeCaseStr
-}
+package processor
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/golang/protobuf/proto"
+	descriptor "github.com/golang/protobuf/protoc-gen-go/descriptor"
+	plugin "github.com/golang/protobuf/protoc-gen-go/plugin"
+)
+
+// ParseRequest parses the input and returns the CodeGeneratorRequest of protoc.
+func ParseRequest(r io.Reader) (*plugin.CodeGeneratorRequest, error) {
+	buf, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var req plugin.CodeGeneratorRequest
+	if err = proto.Unmarshal(buf, &req); err != nil {
+		return nil, err
+	}
+	return &req, nil
+}
+
+// ParseDescriptorSet parses the input serialized by protoc --descriptor_set_out and returns the CodeGeneratorRequest
+// which generates the code of all the services found in it with the parameter, so that the code can be generated outside protoc.
+// The set should be serialized with --include_imports, or the types of the methods defined in the imported files are unknown.
+func ParseDescriptorSet(r io.Reader, parameter string) (*plugin.CodeGeneratorRequest, error) {
+	buf, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var set descriptor.FileDescriptorSet
+	if err = proto.Unmarshal(buf, &set); err != nil {
+		return nil, err
+	}
+	req := plugin.CodeGeneratorRequest{ProtoFile: set.GetFile()}
+	if parameter != "" {
+		req.Parameter = proto.String(parameter)
+	}
+	for _, f := range set.GetFile() {
+		if len(f.GetService()) > 0 {
+			req.FileToGenerate = append(req.FileToGenerate, f.GetName())
+		}
+	}
+	return &req, nil
+}
+
+// ParseParameter parses the parameter of protoc given as --stest_out=key1=value1,key2=value2:path.
+func ParseParameter(parameter string) (map[string]string, error) {
+	params := make(map[string]string)
+	if parameter == "" {
+		return params, nil
+	}
+	for _, p := range strings.Split(parameter, ",") {
+		kv := strings.SplitN(p, "=", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			return nil, fmt.Errorf("the parameter %q is not key=value", p)
+		}
+		params[kv[0]] = kv[1]
+	}
+	return params, nil
+}
+
+// MessageTypes returns the full names of the messages defined in the proto files of the request, including the nested messages,
+// without the leading dot of the type names of the methods.
+func MessageTypes(req *plugin.CodeGeneratorRequest) []string {
+	var messageTypes []string
+	var addMessages func(prefix string, messages []*descriptor.DescriptorProto)
+	addMessages = func(prefix string, messages []*descriptor.DescriptorProto) {
+		for _, m := range messages {
+			name := prefix + m.GetName()
+			messageTypes = append(messageTypes, name)
+			addMessages(name+".", m.GetNestedType())
+		}
+	}
+	for _, f := range req.ProtoFile {
+		prefix := ""
+		if f.GetPackage() != "" {
+			prefix = f.GetPackage() + "."
+		}
+		addMessages(prefix, f.GetMessageType())
+	}
+	return messageTypes
+}
+
+// MethodComments returns the leading comments of the methods of the services defined in the proto files of the request
+// with the names of the services and the methods joined by a dot as the keys, such as Sample.Hello.
+// The comments are found in the SourceCodeInfo of the files, which protoc gives to the plugins.
+func MethodComments(req *plugin.CodeGeneratorRequest) map[string]string {
+	comments := make(map[string]string)
+	for _, f := range req.ProtoFile {
+		for _, location := range f.GetSourceCodeInfo().GetLocation() {
+			// The path of a method is [6 (service), the index of the service, 2 (method), the index of the method].
+			path := location.GetPath()
+			if len(path) != 4 || path[0] != 6 || path[2] != 2 || location.GetLeadingComments() == "" {
+				continue
+			}
+			if int(path[1]) >= len(f.GetService()) || int(path[3]) >= len(f.GetService()[path[1]].GetMethod()) {
+				continue
+			}
+			service := f.GetService()[path[1]]
+			comments[service.GetName()+"."+service.GetMethod()[path[3]].GetName()] = location.GetLeadingComments()
+		}
+	}
+	return comments
+}
+
+// ProcessRequest processes the request and returns a response to generate the code.
+// If genMethodCodeFunc is not nil, the code of each method of the service is also generated in its own file named after the method.
+// If genCLICodeFunc is not nil, the code of the CLI of each service is also generated in its own directory.
+// If genGinkgoCodeFunc is not nil, the code declaring the scenario as the Ginkgo specs is also generated in its own file.
+func ProcessRequest(req *plugin.CodeGeneratorRequest, genCodeFunc, genCLICodeFunc, genGinkgoCodeFunc func(packageName, serviceName string, methods []*descriptor.MethodDescriptorProto) string,
+	genMethodCodeFunc func(packageName, serviceName string, methods []*descriptor.MethodDescriptorProto, methodName string) string) *plugin.CodeGeneratorResponse {
+	files := make(map[string]*descriptor.FileDescriptorProto)
+	for _, f := range req.ProtoFile {
+		files[f.GetName()] = f
+	}
+	// The generated code builds and compares the messages through their fields, which keeps the presence of the optional fields of proto3.
+	res := plugin.CodeGeneratorResponse{SupportedFeatures: proto.Uint64(uint64(plugin.CodeGeneratorResponse_FEATURE_PROTO3_OPTIONAL))}
+	for _, fname := range req.FileToGenerate {
+		f := files[fname]
+		for _, service := range f.GetService() {
+			packageName := f.GetOptions().GetGoPackage()
+			serviceName := service.GetName()
+			methods := service.GetMethod()
+			genCode := genCodeFunc(packageName, serviceName, methods)
+			serviceNameSnakeCase := toSnakeCase(service.GetName())
+			outputFname := serviceNameSnakeCase + "_scenariotest.go"
+			res.File = append(res.File, &plugin.CodeGeneratorResponse_File{
+				Name:    proto.String(outputFname),
+				Content: proto.String(genCode),
+			})
+			if genMethodCodeFunc != nil {
+				for _, method := range methods {
+					res.File = append(res.File, &plugin.CodeGeneratorResponse_File{
+						Name:    proto.String(serviceNameSnakeCase + "_" + toSnakeCase(method.GetName()) + "_scenariotest.go"),
+						Content: proto.String(genMethodCodeFunc(packageName, serviceName, methods, method.GetName())),
+					})
+				}
+			}
+			if genGinkgoCodeFunc != nil {
+				res.File = append(res.File, &plugin.CodeGeneratorResponse_File{
+					Name:    proto.String(serviceNameSnakeCase + "_ginkgo_scenariotest.go"),
+					Content: proto.String(genGinkgoCodeFunc(packageName, serviceName, methods)),
+				})
+			}
+			if genCLICodeFunc != nil {
+				res.File = append(res.File, &plugin.CodeGeneratorResponse_File{
+					Name:    proto.String(serviceNameSnakeCase + "_stest/main.go"),
+					Content: proto.String(genCLICodeFunc(packageName, serviceName, methods)),
+				})
+			}
+		}
+	}
+	return &res
+}
+
+// EmitResponse returns the response of protoc
+func EmitResponse(res *plugin.CodeGeneratorResponse) error {
+	buf, err := proto.Marshal(res)
+	if err != nil {
+		return err
+	}
+	_, err = os.Stdout.Write(buf)
+	return err
+}
+
+// WriteResponse writes the files of the response under dir as protoc does.
+func WriteResponse(res *plugin.CodeGeneratorResponse, dir string) error {
+	if res.Error != nil {
+		return errors.New(res.GetError())
+	}
+	for _, f := range res.File {
+		path := filepath.Join(dir, f.GetName())
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(path, []byte(f.GetContent()), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func toSnakeCase(str string) (snakeCaseStr string) {
+	for i, c := range str {
+		if unicode.IsUpper(c) {
+			lower := strings.ToLower(string(c))
+			if i == 0 {
+				snakeCaseStr = lower
+				continue
+			}
+			snakeCaseStr = snakeCaseStr + "_" + lower
+			continue
+		}
+		snakeCaseStr = snakeCaseStr + string(c)
+	}
+	return snakeCaseStr
+}